@@ -0,0 +1,419 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: judge.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Judge_Submit_FullMethodName         = "/api.Judge/Submit"
+	Judge_GetResult_FullMethodName      = "/api.Judge/GetResult"
+	Judge_StreamProgress_FullMethodName = "/api.Judge/StreamProgress"
+	Judge_ListLanguages_FullMethodName  = "/api.Judge/ListLanguages"
+	Judge_Run_FullMethodName            = "/api.Judge/Run"
+	Judge_Generate_FullMethodName       = "/api.Judge/Generate"
+	Judge_Validate_FullMethodName       = "/api.Judge/Validate"
+	Judge_Stress_FullMethodName         = "/api.Judge/Stress"
+)
+
+// JudgeClient is the client API for Judge service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Judge runs submitted code against the tests of a match's problem. It is
+// served by cmd/judge so judge nodes scale and fail apart from the API.
+type JudgeClient interface {
+	// Submit stores a submission and queues it for judging
+	Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*Submission, error)
+	// GetResult returns a submission with its progress and verdict
+	GetResult(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*Submission, error)
+	// StreamProgress sends the submission whenever it changes and ends once
+	// it is judged
+	StreamProgress(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Submission], error)
+	// ListLanguages returns the languages the judge accepts
+	ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error)
+	// Run runs code once on custom input without storing or scoring it
+	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResult, error)
+	// Generate returns the problem with its generated tests replaced by the
+	// output of its generators and reference solution
+	Generate(ctx context.Context, in *ProblemRequest, opts ...grpc.CallOption) (*ProblemRequest, error)
+	// Validate runs the problem's reference and wrong solutions on its tests
+	Validate(ctx context.Context, in *ProblemRequest, opts ...grpc.CallOption) (*ValidationReport, error)
+	// Stress compares a solution with the problem's reference on generated
+	// inputs
+	Stress(ctx context.Context, in *StressRequest, opts ...grpc.CallOption) (*StressResult, error)
+}
+
+type judgeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJudgeClient(cc grpc.ClientConnInterface) JudgeClient {
+	return &judgeClient{cc}
+}
+
+func (c *judgeClient) Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*Submission, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Submission)
+	err := c.cc.Invoke(ctx, Judge_Submit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) GetResult(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*Submission, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Submission)
+	err := c.cc.Invoke(ctx, Judge_GetResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) StreamProgress(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Submission], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Judge_ServiceDesc.Streams[0], Judge_StreamProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetResultRequest, Submission]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Judge_StreamProgressClient = grpc.ServerStreamingClient[Submission]
+
+func (c *judgeClient) ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLanguagesResponse)
+	err := c.cc.Invoke(ctx, Judge_ListLanguages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunResult)
+	err := c.cc.Invoke(ctx, Judge_Run_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) Generate(ctx context.Context, in *ProblemRequest, opts ...grpc.CallOption) (*ProblemRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProblemRequest)
+	err := c.cc.Invoke(ctx, Judge_Generate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) Validate(ctx context.Context, in *ProblemRequest, opts ...grpc.CallOption) (*ValidationReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidationReport)
+	err := c.cc.Invoke(ctx, Judge_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *judgeClient) Stress(ctx context.Context, in *StressRequest, opts ...grpc.CallOption) (*StressResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StressResult)
+	err := c.cc.Invoke(ctx, Judge_Stress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JudgeServer is the server API for Judge service.
+// All implementations must embed UnimplementedJudgeServer
+// for forward compatibility.
+//
+// Judge runs submitted code against the tests of a match's problem. It is
+// served by cmd/judge so judge nodes scale and fail apart from the API.
+type JudgeServer interface {
+	// Submit stores a submission and queues it for judging
+	Submit(context.Context, *SubmitRequest) (*Submission, error)
+	// GetResult returns a submission with its progress and verdict
+	GetResult(context.Context, *GetResultRequest) (*Submission, error)
+	// StreamProgress sends the submission whenever it changes and ends once
+	// it is judged
+	StreamProgress(*GetResultRequest, grpc.ServerStreamingServer[Submission]) error
+	// ListLanguages returns the languages the judge accepts
+	ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error)
+	// Run runs code once on custom input without storing or scoring it
+	Run(context.Context, *RunRequest) (*RunResult, error)
+	// Generate returns the problem with its generated tests replaced by the
+	// output of its generators and reference solution
+	Generate(context.Context, *ProblemRequest) (*ProblemRequest, error)
+	// Validate runs the problem's reference and wrong solutions on its tests
+	Validate(context.Context, *ProblemRequest) (*ValidationReport, error)
+	// Stress compares a solution with the problem's reference on generated
+	// inputs
+	Stress(context.Context, *StressRequest) (*StressResult, error)
+	mustEmbedUnimplementedJudgeServer()
+}
+
+// UnimplementedJudgeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedJudgeServer struct{}
+
+func (UnimplementedJudgeServer) Submit(context.Context, *SubmitRequest) (*Submission, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedJudgeServer) GetResult(context.Context, *GetResultRequest) (*Submission, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResult not implemented")
+}
+func (UnimplementedJudgeServer) StreamProgress(*GetResultRequest, grpc.ServerStreamingServer[Submission]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProgress not implemented")
+}
+func (UnimplementedJudgeServer) ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLanguages not implemented")
+}
+func (UnimplementedJudgeServer) Run(context.Context, *RunRequest) (*RunResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Run not implemented")
+}
+func (UnimplementedJudgeServer) Generate(context.Context, *ProblemRequest) (*ProblemRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedJudgeServer) Validate(context.Context, *ProblemRequest) (*ValidationReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedJudgeServer) Stress(context.Context, *StressRequest) (*StressResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stress not implemented")
+}
+func (UnimplementedJudgeServer) mustEmbedUnimplementedJudgeServer() {}
+func (UnimplementedJudgeServer) testEmbeddedByValue()               {}
+
+// UnsafeJudgeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JudgeServer will
+// result in compilation errors.
+type UnsafeJudgeServer interface {
+	mustEmbedUnimplementedJudgeServer()
+}
+
+func RegisterJudgeServer(s grpc.ServiceRegistrar, srv JudgeServer) {
+	// If the following call pancis, it indicates UnimplementedJudgeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Judge_ServiceDesc, srv)
+}
+
+func _Judge_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).Submit(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_GetResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).GetResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_GetResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).GetResult(ctx, req.(*GetResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_StreamProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetResultRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JudgeServer).StreamProgress(m, &grpc.GenericServerStream[GetResultRequest, Submission]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Judge_StreamProgressServer = grpc.ServerStreamingServer[Submission]
+
+func _Judge_ListLanguages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLanguagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).ListLanguages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_ListLanguages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).ListLanguages(ctx, req.(*ListLanguagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).Run(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_Run_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).Run(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProblemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).Generate(ctx, req.(*ProblemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProblemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).Validate(ctx, req.(*ProblemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Judge_Stress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JudgeServer).Stress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Judge_Stress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JudgeServer).Stress(ctx, req.(*StressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Judge_ServiceDesc is the grpc.ServiceDesc for Judge service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Judge_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Judge",
+	HandlerType: (*JudgeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _Judge_Submit_Handler,
+		},
+		{
+			MethodName: "GetResult",
+			Handler:    _Judge_GetResult_Handler,
+		},
+		{
+			MethodName: "ListLanguages",
+			Handler:    _Judge_ListLanguages_Handler,
+		},
+		{
+			MethodName: "Run",
+			Handler:    _Judge_Run_Handler,
+		},
+		{
+			MethodName: "Generate",
+			Handler:    _Judge_Generate_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _Judge_Validate_Handler,
+		},
+		{
+			MethodName: "Stress",
+			Handler:    _Judge_Stress_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProgress",
+			Handler:       _Judge_StreamProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "judge.proto",
+}
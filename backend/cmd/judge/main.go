@@ -1,4 +1,102 @@
 package main
 
-func main() {}
+import (
+	"context"
+	"log"
+	"net"
+	"os/signal"
+	"syscall"
 
+	"coderoulette/api"
+	"coderoulette/internal/config"
+	"coderoulette/internal/database"
+	"coderoulette/internal/sandbox"
+	"coderoulette/internal/services"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Initialize configuration
+	cfg := config.Load()
+
+	// Initialize database
+	db, err := database.Initialize(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// Initialize Redis
+	redisClient, err := database.InitializeRedis(cfg.RedisURL)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+
+	ratings, err := services.NewRatingSystem(cfg.RatingSystem, cfg.EloKFactor)
+	if err != nil {
+		log.Fatal("Invalid rating configuration:", err)
+	}
+
+	// Initialize judge
+	judgeService := services.NewJudgeService()
+	judgeService.SetDB(db)
+	judgeService.SetSandbox(sandbox.New(cfg.SandboxCgroupRoot))
+	judgeService.SetQueue(services.NewJudgeQueue(redisClient, int64(cfg.JudgeQueueMax)))
+	judgeService.SetTestParallelism(cfg.JudgeTestWorkers)
+	judgeService.SetRatingSystem(ratings)
+	judgeService.SetAnalysis(cfg.JudgeAnalysis)
+
+	plagiarismService := services.NewPlagiarismService(db)
+	plagiarismService.SetThreshold(cfg.PlagiarismThreshold)
+	judgeService.SetPlagiarism(plagiarismService)
+
+	languages, err := services.LoadLanguageRegistry(cfg.LanguagesConfig)
+	if err != nil {
+		log.Printf("Using built-in languages, failed to load %s: %v", cfg.LanguagesConfig, err)
+	} else {
+		judgeService.SetLanguages(languages)
+	}
+
+	compileCache, err := services.NewCompileCache(cfg.CompileCacheDir, int64(cfg.CompileCacheMaxMB)<<20)
+	if err != nil {
+		log.Printf("Compile cache disabled: %v", err)
+	} else {
+		judgeService.SetCompileCache(compileCache)
+	}
+
+	// Stop taking new jobs on SIGINT/SIGTERM, finishing the ones in progress
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	judgeService.WarmBuildCaches(ctx)
+
+	// Serve the judge API for the API servers
+	listener, err := net.Listen("tcp", cfg.JudgeGRPCAddr)
+	if err != nil {
+		log.Fatal("Failed to listen for the judge API:", err)
+	}
+	server := grpc.NewServer()
+	api.RegisterJudgeServer(server, services.NewJudgeServer(judgeService))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Printf("Judge API stopped: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	log.Printf("Judge API listening on %s", cfg.JudgeGRPCAddr)
+
+	log.Printf("Judge starting with %d workers", cfg.JudgeWorkers)
+	if err := judgeService.RunWorkers(ctx, cfg.JudgeWorkers); err != nil {
+		log.Fatal("Judge workers failed:", err)
+	}
+	log.Println("Judge stopped")
+}
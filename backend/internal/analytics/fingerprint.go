@@ -0,0 +1,183 @@
+// Package analytics compares submitted code for plagiarism using winnowing
+// over normalized token streams (Schleimer et al., "Winnowing: Local
+// Algorithms for Document Fingerprinting").
+package analytics
+
+import (
+	"hash/fnv"
+	"unicode"
+)
+
+// Fingerprinting parameters. A match must span at least K tokens to count,
+// and any shared run of K+W-1 tokens is guaranteed to be detected.
+const (
+	K = 5 // tokens per k-gram
+	W = 4 // k-grams per winnowing window
+)
+
+// Placeholders identifiers, numbers and strings are normalized to, so
+// renaming variables or changing constants does not hide a copy
+const (
+	tokenIdent  = "ID"
+	tokenNumber = "NUM"
+	tokenString = "STR"
+)
+
+// Fingerprint is the set of selected k-gram hashes of a program
+type Fingerprint map[uint64]struct{}
+
+// Tokenize splits source code into normalized tokens: keywords and
+// operators are kept, identifiers, numbers and string literals are
+// replaced by placeholders and comments and whitespace are dropped
+func Tokenize(language, code string) []string {
+	keywords := languageKeywords[language]
+	hashComments := language == "python"
+	src := []rune(code)
+	var tokens []string
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/', c == '#' && hashComments:
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			i += 2
+			for i+1 < len(src) && !(src[i] == '*' && src[i+1] == '/') {
+				i++
+			}
+			i += 2
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(src, i)
+			tokens = append(tokens, tokenString)
+		case unicode.IsDigit(c):
+			for i < len(src) && (unicode.IsDigit(src[i]) || unicode.IsLetter(src[i]) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, tokenNumber)
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(src[i]) || unicode.IsDigit(src[i]) || src[i] == '_') {
+				i++
+			}
+			word := string(src[start:i])
+			if keywords[word] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, tokenIdent)
+			}
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipString returns the index after the string literal starting at i,
+// including Python's triple-quoted strings
+func skipString(src []rune, i int) int {
+	quote := src[i]
+	if i+2 < len(src) && src[i+1] == quote && src[i+2] == quote {
+		for i += 3; i+2 < len(src); i++ {
+			if src[i] == quote && src[i+1] == quote && src[i+2] == quote {
+				return i + 3
+			}
+		}
+		return len(src)
+	}
+
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// Winnow fingerprints a token stream: it hashes every k-gram and keeps the
+// minimum hash of each window of W consecutive k-grams
+func Winnow(tokens []string) Fingerprint {
+	fingerprint := make(Fingerprint)
+	if len(tokens) < K {
+		return fingerprint
+	}
+
+	hashes := make([]uint64, len(tokens)-K+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+K] {
+			h.Write([]byte(token))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+
+	if len(hashes) < W {
+		for _, h := range hashes {
+			fingerprint[h] = struct{}{}
+		}
+		return fingerprint
+	}
+	for start := 0; start+W <= len(hashes); start++ {
+		// The rightmost minimum, so equal hashes in a row are picked once
+		minimum := start
+		for i := start + 1; i < start+W; i++ {
+			if hashes[i] <= hashes[minimum] {
+				minimum = i
+			}
+		}
+		fingerprint[hashes[minimum]] = struct{}{}
+	}
+	return fingerprint
+}
+
+// Fingerprints tokenizes and winnows source code
+func Fingerprints(language, code string) Fingerprint {
+	return Winnow(Tokenize(language, code))
+}
+
+// Without returns the fingerprint minus the hashes in base, such as those
+// of the starter code every player receives
+func (f Fingerprint) Without(base Fingerprint) Fingerprint {
+	if len(base) == 0 {
+		return f
+	}
+	rest := make(Fingerprint, len(f))
+	for h := range f {
+		if _, ok := base[h]; !ok {
+			rest[h] = struct{}{}
+		}
+	}
+	return rest
+}
+
+// Similarity is the share of the smaller fingerprint found in the other
+// one, from 0 to 1, so code copied into a longer program still scores high
+func Similarity(a, b Fingerprint) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return 0
+	}
+	shared := 0
+	for h := range a {
+		if _, ok := b[h]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
@@ -0,0 +1,199 @@
+package analytics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name           string
+		language, code string
+		want           []string
+	}{
+		{"identifiers and numbers", "go", "x := 42", []string{"ID", ":", "=", "NUM"}},
+		{"keywords kept", "go", "return total", []string{"return", "ID"}},
+		{"line comment", "go", "x // y z\ny", []string{"ID", "ID"}},
+		{"block comment", "c", "a /* b\nc */ d", []string{"ID", "ID"}},
+		{"hash comment in python", "python", "x # y\nz", []string{"ID", "ID"}},
+		{"hash outside python", "go", "#x", []string{"#", "ID"}},
+		{"escaped quote", "go", `s = "a\"b" + t`, []string{"ID", "=", "STR", "+", "ID"}},
+		{"triple-quoted string", "python", "x = \"\"\"a\n\"b\"\n\"\"\"\ny", []string{"ID", "=", "STR", "ID"}},
+		{"backtick string", "go", "x := `a\n\"b\"\n` + y", []string{"ID", ":", "=", "STR", "+", "ID"}},
+		{"unknown language", "cobol", "if x", []string{"ID", "ID"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.language, tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipString(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int
+	}{
+		{"double quotes", `"ab" c`, 4},
+		{"single quotes", `'a' c`, 3},
+		{"escaped quote", `"a\"b" c`, 6},
+		{"unterminated at newline", "\"ab\nc", 3},
+		{"unterminated at end", `"ab`, 3},
+		{"backtick spans lines", "`a\nb` c", 5},
+		{"backtick has no escapes", "`a\\` c", 4},
+		{"triple quotes", `"""a"b""" c`, 9},
+		{"triple quotes span lines", "'''a\n'''", 8},
+		{"unterminated triple quotes", `"""ab"`, 6},
+		{"empty string", `"" c`, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skipString([]rune(tt.src), 0); got != tt.want {
+				t.Errorf("skipString(%q) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinnow(t *testing.T) {
+	tokens := strings.Fields("a b c d e f g h i j k l")
+
+	tests := []struct {
+		name   string
+		tokens []string
+		want   int // number of hashes, -1 for at least one
+	}{
+		{"no tokens", nil, 0},
+		{"shorter than a k-gram", tokens[:K-1], 0},
+		{"a single k-gram", tokens[:K], 1},
+		{"fewer k-grams than a window", tokens[:K+W-2], W - 1},
+		{"repeated k-gram", strings.Fields(strings.Repeat("a ", K+W)), 1},
+		{"several windows", tokens, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Winnow(tt.tokens)
+			if tt.want >= 0 && len(got) != tt.want || tt.want < 0 && len(got) == 0 {
+				t.Errorf("Winnow selected %d hashes, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	// Every window keeps one of its hashes, so a long enough shared run of
+	// tokens always shares a hash
+	whole := Winnow(tokens)
+	if shared := Winnow(tokens[2 : 2+K+W-1]); Similarity(shared, whole) == 0 {
+		t.Error("a shared run of K+W-1 tokens was not detected")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	const program = `
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}`
+	const renamed = `
+// Adds up numbers
+func add(numbers []int) int {
+	acc := 1
+	for _, n := range numbers {
+		acc += n
+	}
+	return acc
+}`
+	const other = `
+func max(values []int) int {
+	if len(values) == 0 {
+		panic("empty")
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		if v > best {
+			best = v
+		}
+	}
+	return best
+}`
+
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{"identical", program, program, 1, 1},
+		{"renamed identifiers and constants", program, renamed, 1, 1},
+		{"copied into a longer program", program, program + other, 1, 1},
+		{"different programs", program, other, 0, 0.5},
+		{"too short to fingerprint", "x", program, 0, 0},
+		{"both empty", "", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(Fingerprints("go", tt.a), Fingerprints("go", tt.b))
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity = %.3f, want %.3f to %.3f", got, tt.min, tt.max)
+			}
+			if reverse := Similarity(Fingerprints("go", tt.b), Fingerprints("go", tt.a)); reverse != got {
+				t.Errorf("Similarity is not symmetric: %.3f and %.3f", got, reverse)
+			}
+		})
+	}
+}
+
+func TestWithout(t *testing.T) {
+	const starter = `
+package main
+
+import "fmt"
+
+func main() {
+	var n int
+	fmt.Scan(&n)
+}`
+	const solution = `
+package main
+
+import "fmt"
+
+func main() {
+	var n int
+	fmt.Scan(&n)
+	total := 0
+	for i := 1; i <= n; i++ {
+		if i%3 == 0 || i%5 == 0 {
+			total += i
+		}
+	}
+	fmt.Println(total)
+}`
+
+	base := Fingerprints("go", starter)
+	full := Fingerprints("go", solution)
+	rest := full.Without(base)
+
+	if len(rest) == 0 || len(rest) >= len(full) {
+		t.Fatalf("Without kept %d of %d hashes, want some but not all", len(rest), len(full))
+	}
+	for h := range rest {
+		if _, ok := base[h]; ok {
+			t.Errorf("hash %x of the starter code was kept", h)
+		}
+	}
+	if got := base.Without(base); len(got) != 0 {
+		t.Errorf("starter code without itself has %d hashes, want none", len(got))
+	}
+	if got := full.Without(nil); len(got) != len(full) {
+		t.Errorf("Without(nil) has %d hashes, want %d", len(got), len(full))
+	}
+	if got := Similarity(base.Without(base), full.Without(base)); got != 0 {
+		t.Errorf("Similarity of the starter code alone = %.3f, want 0", got)
+	}
+}
@@ -0,0 +1,48 @@
+package analytics
+
+import "strings"
+
+// languageKeywords are the words kept as they are when tokenizing, other
+// identifiers become placeholders. Languages not listed here still work,
+// with every word treated as an identifier.
+var languageKeywords = map[string]map[string]bool{
+	"c": words(`auto break case char const continue default do double else enum
+		extern float for goto if inline int long register return short signed
+		sizeof static struct switch typedef union unsigned void volatile while`),
+	"cpp": words(`auto bool break case catch char class const constexpr continue
+		default delete do double else enum explicit false float for friend goto
+		if inline int long namespace new nullptr operator private protected
+		public return short signed sizeof static struct switch template this
+		throw true try typedef typename union unsigned using virtual void while`),
+	"go": words(`break case chan const continue default defer else fallthrough
+		for func go goto if import interface map package range return select
+		struct switch type var`),
+	"java": words(`abstract boolean break byte case catch char class continue
+		default do double else enum extends final finally float for if
+		implements import instanceof int interface long new null package
+		private protected public return short static super switch this throw
+		throws try void while`),
+	"javascript": words(`async await break case catch class const continue
+		default delete do else export extends false finally for function if
+		import in instanceof let new null of return static super switch this
+		throw true try typeof undefined var void while yield`),
+	"typescript": words(`any async await boolean break case catch class const
+		continue default delete do else enum export extends false finally for
+		function if implements import in instanceof interface let new null
+		number of private protected public readonly return static string super
+		switch this throw true try type typeof undefined var void while yield`),
+	"python": words(`and as assert async await break class continue def del
+		elif else except False finally for from global if import in is lambda
+		None nonlocal not or pass raise return True try while with yield`),
+	"rust": words(`as async await break const continue crate else enum extern
+		false fn for if impl in let loop match mod move mut pub ref return self
+		Self static struct super trait true type unsafe use where while`),
+}
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
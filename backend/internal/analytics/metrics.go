@@ -0,0 +1,39 @@
+package analytics
+
+import "strings"
+
+// Metrics are language independent measures of a program's size and shape
+type Metrics struct {
+	Lines      int `json:"lines"`      // non-blank lines
+	Complexity int `json:"complexity"` // cyclomatic complexity of the whole program
+}
+
+// branchKeywords each add a path through the program
+var branchKeywords = map[string]bool{
+	"if": true, "elif": true, "for": true, "while": true, "loop": true,
+	"case": true, "catch": true, "except": true, "and": true, "or": true,
+}
+
+// Measure counts a program's lines and approximates its cyclomatic
+// complexity as one plus its branches: conditionals, loops, switch cases,
+// exception handlers and short-circuit boolean operators
+func Measure(language, code string) Metrics {
+	metrics := Metrics{Complexity: 1}
+	for _, line := range strings.Split(code, "\n") {
+		if strings.TrimSpace(line) != "" {
+			metrics.Lines++
+		}
+	}
+
+	tokens := Tokenize(language, code)
+	for i, token := range tokens {
+		switch {
+		case branchKeywords[token]:
+			metrics.Complexity++
+		case (token == "&" || token == "|") && i > 0 && tokens[i-1] == token && (i < 2 || tokens[i-2] != token):
+			// && and || are tokenized one rune at a time
+			metrics.Complexity++
+		}
+	}
+	return metrics
+}
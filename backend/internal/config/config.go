@@ -1,12 +1,48 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 type Config struct {
 	DatabaseURL string
 	RedisURL    string
 	JWTSecret   string
 	Port        string
+	AdminToken  string
+
+	RunRateLimit int // custom input runs per client and match per minute, 0 for unlimited
+
+	SandboxCgroupRoot string
+	JudgeWorkers      int
+	JudgeQueueMax     int
+	JudgeTestWorkers  int
+	LanguagesConfig   string
+	JudgeGRPCAddr     string // where cmd/judge serves the judge API
+	JudgeAddr         string // judge service the API server calls, empty judges in process
+	JudgeAnalysis     bool   // attach linter findings and code metrics to judged submissions
+
+	CompileCacheDir   string
+	CompileCacheMaxMB int
+
+	PlagiarismThreshold int // similarity in percent from which pairs are flagged
+
+	RatingSystem string // glicko2 or elo
+	EloKFactor   int
+
+	MatchRatingGap       int // rating gap accepted right away
+	MatchGapPerSecond    int // growth of the accepted gap while waiting
+	MatchMaxRatingGap    int
+	MatchRelaxDifficulty int // seconds before other difficulties are accepted, 0 never
+	MatchRelaxLanguage   int // seconds before other languages are accepted, 0 never
+
+	// Match timers in seconds
+	MatchReadyTimeout   int // to join the room, and again to confirm
+	MatchCountdown      int
+	MatchDurationEasy   int
+	MatchDurationMedium int
+	MatchDurationHard   int
 }
 
 func Load() *Config {
@@ -15,6 +51,38 @@ func Load() *Config {
 		RedisURL:    getEnv("REDIS_URL", "redis://localhost:6379"),
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
 		Port:        getEnv("PORT", "8080"),
+		AdminToken:  getEnv("ADMIN_TOKEN", ""),
+
+		RunRateLimit: getEnvInt("RUN_RATE_LIMIT", 10),
+
+		SandboxCgroupRoot: getEnv("SANDBOX_CGROUP_ROOT", "/sys/fs/cgroup/coderoulette"),
+		JudgeWorkers:      getEnvInt("JUDGE_WORKERS", 4),
+		JudgeQueueMax:     getEnvInt("JUDGE_QUEUE_MAX", 1000),
+		JudgeTestWorkers:  getEnvInt("JUDGE_TEST_WORKERS", 2),
+		LanguagesConfig:   getEnv("LANGUAGES_CONFIG", "deployments/configs/languages.json"),
+		JudgeGRPCAddr:     getEnv("JUDGE_GRPC_ADDR", ":9090"),
+		JudgeAddr:         getEnv("JUDGE_ADDR", ""),
+		JudgeAnalysis:     getEnvBool("JUDGE_ANALYSIS", true),
+
+		CompileCacheDir:   getEnv("COMPILE_CACHE_DIR", "/var/cache/coderoulette/builds"),
+		CompileCacheMaxMB: getEnvInt("COMPILE_CACHE_MAX_MB", 1024),
+
+		PlagiarismThreshold: getEnvInt("PLAGIARISM_THRESHOLD", 80),
+
+		RatingSystem: getEnv("RATING_SYSTEM", "glicko2"),
+		EloKFactor:   getEnvInt("ELO_K_FACTOR", 32),
+
+		MatchRatingGap:       getEnvInt("MATCH_RATING_GAP", 100),
+		MatchGapPerSecond:    getEnvInt("MATCH_GAP_PER_SECOND", 10),
+		MatchMaxRatingGap:    getEnvInt("MATCH_MAX_RATING_GAP", 800),
+		MatchRelaxDifficulty: getEnvInt("MATCH_RELAX_DIFFICULTY_AFTER", 60),
+		MatchRelaxLanguage:   getEnvInt("MATCH_RELAX_LANGUAGE_AFTER", 0),
+
+		MatchReadyTimeout:   getEnvInt("MATCH_READY_TIMEOUT", 30),
+		MatchCountdown:      getEnvInt("MATCH_COUNTDOWN", 5),
+		MatchDurationEasy:   getEnvInt("MATCH_DURATION_EASY", 900),
+		MatchDurationMedium: getEnvInt("MATCH_DURATION_MEDIUM", 1800),
+		MatchDurationHard:   getEnvInt("MATCH_DURATION_HARD", 2700),
 	}
 }
 
@@ -24,3 +92,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
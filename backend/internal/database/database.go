@@ -2,6 +2,7 @@ package database
 
 import (
 	"log"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,12 +23,30 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 		&Problem{},
 		&Match{},
 		&Submission{},
+		&SubmissionHistory{},
 		&Report{},
 		&SkillCard{},
+		&SimilarityFlag{},
+		&RatingHistory{},
 	); err != nil {
 		return nil, err
 	}
+	if err := migrateMatches(db); err != nil {
+		return nil, err
+	}
 
 	log.Println("Database connected and migrated successfully")
 	return db, nil
 }
+
+// migrateMatches moves matches from before the match states to them. They
+// waited as "waiting" and played without a deadline, so they are given one
+// that has passed for the match timers to abort or finish them.
+func migrateMatches(db *gorm.DB) error {
+	if err := db.Model(&Match{}).Where("status = ?", "waiting").Update("status", "matched").Error; err != nil {
+		return err
+	}
+	return db.Model(&Match{}).
+		Where("status IN ? AND deadline IS NULL", []string{"matched", "active"}).
+		Update("deadline", time.Now()).Error
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMigrateMatches(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true, // would connect
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	var statements []string
+	db.Callback().Update().After("gorm:update").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+
+	if err := migrateMatches(db); err != nil {
+		t.Fatalf("migrateMatches: %v", err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("ran %d updates, want 2: %q", len(statements), statements)
+	}
+	if !strings.Contains(statements[0], `SET "status"`) || !strings.Contains(statements[0], "WHERE status = ") {
+		t.Errorf("first update %q does not rename waiting matches", statements[0])
+	}
+	if !strings.Contains(statements[1], `SET "deadline"`) || !strings.Contains(statements[1], "deadline IS NULL") {
+		t.Errorf("second update %q does not give matches a deadline", statements[1])
+	}
+}
@@ -18,6 +18,10 @@ type User struct {
 	Losses    int       `gorm:"default:0" json:"losses"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Glicko-2 uncertainty of Rating, unused by Elo
+	RatingDeviation float64 `gorm:"default:350" json:"rating_deviation"`
+	Volatility      float64 `gorm:"default:0.06" json:"volatility"`
 }
 
 // Problem represents a coding problem
@@ -26,11 +30,41 @@ type Problem struct {
 	Title       string    `gorm:"not null" json:"title"`
 	Description string    `gorm:"type:text;not null" json:"description"`
 	Difficulty  string    `gorm:"not null" json:"difficulty"`   // easy, medium, hard
-	Language    string    `gorm:"not null" json:"language"`     // go, python, javascript
+	Language    string    `gorm:"not null" json:"language"`     // a language registered with the judge
 	TestCases   string    `gorm:"type:jsonb" json:"test_cases"` // JSON array of test cases
 	Solution    string    `gorm:"type:text" json:"solution"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Sandbox limits applied to every test run, 0 means the language default
+	TimeLimit    int `json:"time_limit"`    // CPU time in milliseconds
+	MemoryLimit  int `json:"memory_limit"`  // in megabytes
+	ProcessLimit int `json:"process_limit"` // processes and threads
+	OutputLimit  int `json:"output_limit"`  // in kilobytes
+
+	// Function problems: JSON signature of the function players implement,
+	// empty for problems that read stdin and write stdout
+	Signature string `gorm:"type:text" json:"signature"`
+
+	// JSON output checker settings, empty means exact comparison
+	Checker string `gorm:"type:text" json:"checker"`
+
+	// Interactive problems run the player's program against an interactor,
+	// stored as JSON with its code and language
+	Type       string `gorm:"default:'standard'" json:"type"` // standard, interactive
+	Interactor string `gorm:"type:text" json:"interactor"`
+
+	// JSON array of subtasks grouping the tests, empty for per-test scoring
+	Subtasks string `gorm:"type:jsonb;default:'[]'" json:"subtasks"`
+
+	// Validation runs the reference solution and the known-wrong ones (a
+	// JSON array) through the judge; invalid problems are kept out of matches
+	WrongSolutions   string `gorm:"type:text" json:"wrong_solutions"`
+	ValidationStatus string `gorm:"default:'unvalidated'" json:"validation_status"` // unvalidated, valid, invalid
+	ValidationReport string `gorm:"type:text" json:"validation_report"`            // JSON report of the last run
+
+	// JSON array of test input generators and their seeds
+	Generators string `gorm:"type:text" json:"generators"`
 }
 
 // Match represents a match between two players
@@ -39,12 +73,28 @@ type Match struct {
 	Player1ID uuid.UUID  `gorm:"not null" json:"player1_id"`
 	Player2ID uuid.UUID  `gorm:"not null" json:"player2_id"`
 	ProblemID uuid.UUID  `gorm:"not null" json:"problem_id"`
-	Status    string     `gorm:"default:'waiting'" json:"status"` // waiting, active, completed
+	Status    string     `gorm:"default:'matched'" json:"status"` // matched, ready_check, countdown, active, judging_final, completed, aborted, forfeited
 	WinnerID  *uuid.UUID `json:"winner_id"`
 	Duration  int        `json:"duration"` // in seconds
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 
+	// Queue the players were matched in
+	Difficulty string `json:"difficulty"`
+	Language   string `json:"language"`
+
+	// Lifecycle: the current state ends at Deadline, players confirm they
+	// are ready and play runs from StartedAt to EndedAt
+	Deadline     *time.Time `gorm:"index" json:"deadline"`
+	Player1Ready bool       `json:"player1_ready"`
+	Player2Ready bool       `json:"player2_ready"`
+	StartedAt    *time.Time `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+
+	// Rating changes applied when the match completed
+	Player1RatingDelta int `json:"player1_rating_delta"`
+	Player2RatingDelta int `json:"player2_rating_delta"`
+
 	// Relations
 	Player1 User    `gorm:"foreignKey:Player1ID" json:"player1"`
 	Player2 User    `gorm:"foreignKey:Player2ID" json:"player2"`
@@ -59,17 +109,90 @@ type Submission struct {
 	PlayerID  uuid.UUID `gorm:"not null" json:"player_id"`
 	Code      string    `gorm:"type:text;not null" json:"code"`
 	Language  string    `gorm:"not null" json:"language"`
-	Status    string    `gorm:"default:'pending'" json:"status"` // pending, running, passed, failed
+	Status    string    `gorm:"default:'pending'" json:"status"` // pending, running, done, error
 	Score     int       `json:"score"`
-	Runtime   int       `json:"runtime"` // in milliseconds
+	Runtime   int       `json:"runtime"` // CPU time of all tests in milliseconds
 	ErrorMsg  string    `gorm:"type:text" json:"error_msg"`
 	CreatedAt time.Time `json:"created_at"`
 
+	// Judging outcome
+	Verdict     string `json:"verdict"`                                     // AC, WA, TLE, MLE, RE, OLE, CE
+	TestResults string `gorm:"type:jsonb;default:'[]'" json:"test_results"` // JSON array of per-test results
+	TestsDone   int    `json:"tests_done"`
+	TestsTotal  int    `json:"tests_total"`
+	Memory      int    `json:"memory"` // peak memory of any test in kilobytes
+	Subtasks    string `gorm:"type:jsonb;default:'[]'" json:"subtasks"` // JSON array of per-subtask scores
+	Mode        string `gorm:"default:'run_all'" json:"mode"`           // run_all or stop_on_failure
+
+	// JSON code quality feedback (metrics and linter findings), empty when
+	// analysis is disabled; it never affects the verdict
+	Analysis string `gorm:"type:text" json:"analysis"`
+
+	// JSON options of a rejudge requested while the submission was being
+	// judged, it is queued again once the running job finishes
+	PendingRejudge string `gorm:"type:text" json:"-"`
+
 	// Relations
 	Match  Match `gorm:"foreignKey:MatchID" json:"match"`
 	Player User  `gorm:"foreignKey:PlayerID" json:"player"`
 }
 
+// SubmissionHistory is a judging outcome of a submission that was replaced
+// by a rejudge
+type SubmissionHistory struct {
+	BaseIDModel
+	SubmissionID uuid.UUID `gorm:"not null;index" json:"submission_id"`
+	Status       string    `json:"status"`
+	Verdict      string    `json:"verdict"`
+	Score        int       `json:"score"`
+	Runtime      int       `json:"runtime"`
+	Memory       int       `json:"memory"`
+	ErrorMsg     string    `gorm:"type:text" json:"error_msg"`
+	TestResults  string    `gorm:"type:jsonb;default:'[]'" json:"test_results"`
+	Subtasks     string    `gorm:"type:jsonb;default:'[]'" json:"subtasks"`
+	Reason       string    `gorm:"type:text" json:"reason"` // why it was rejudged
+	CreatedAt    time.Time `json:"created_at"`               // when it was rejudged
+}
+
+// SimilarityFlag is a pair of submissions to the same problem whose code is
+// suspiciously alike, waiting for or carrying a moderator's decision
+type SimilarityFlag struct {
+	BaseIDModel
+	ProblemID     uuid.UUID  `gorm:"not null;index" json:"problem_id"`
+	SubmissionAID uuid.UUID  `gorm:"not null;index" json:"submission_a_id"`
+	SubmissionBID *uuid.UUID `gorm:"index" json:"submission_b_id"` // nil when compared with the reference solution
+	PlayerAID     uuid.UUID  `gorm:"not null" json:"player_a_id"`
+	PlayerBID     *uuid.UUID `json:"player_b_id"`
+	Kind          string     `gorm:"not null" json:"kind"`               // same_match, cross_match, reference
+	Similarity    float64    `json:"similarity"`                         // share of fingerprints in common, from 0 to 1
+	Status        string     `gorm:"default:'open';index" json:"status"` // open, confirmed, dismissed
+	Note          string     `gorm:"type:text" json:"note"`              // moderator's note
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relations
+	SubmissionA Submission  `gorm:"foreignKey:SubmissionAID" json:"submission_a"`
+	SubmissionB *Submission `gorm:"foreignKey:SubmissionBID" json:"submission_b"`
+}
+
+// RatingHistory is a player's rating change from one match. The rating
+// before the match is kept so a rejudge can rate the match again.
+type RatingHistory struct {
+	BaseIDModel
+	UserID           uuid.UUID `gorm:"not null;uniqueIndex:idx_rating_history_user_match" json:"user_id"`
+	MatchID          uuid.UUID `gorm:"not null;uniqueIndex:idx_rating_history_user_match;index" json:"match_id"`
+	Result           string    `gorm:"not null" json:"result"` // win, loss, draw
+	RatingBefore     int       `json:"rating_before"`
+	Rating           int       `json:"rating"`
+	RatingDelta      int       `json:"rating_delta"`
+	DeviationBefore  float64   `json:"deviation_before"`
+	Deviation        float64   `json:"deviation"`
+	VolatilityBefore float64   `json:"volatility_before"`
+	Volatility       float64   `json:"volatility"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
 // Report represents a match report
 type Report struct {
 	BaseIDModel
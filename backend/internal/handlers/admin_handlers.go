@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"coderoulette/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// rejudgeSubmission queues a single submission for judging again
+func (h *Handlers) rejudgeSubmission(c *gin.Context) {
+	h.rejudge(c, "id", "invalid submission ID", h.judgeService.RejudgeSubmission)
+}
+
+// rejudgeMatch queues all submissions of a match for judging again
+func (h *Handlers) rejudgeMatch(c *gin.Context) {
+	h.rejudge(c, "matchId", "invalid match ID", h.judgeService.RejudgeMatch)
+}
+
+// rejudgeProblem queues all submissions to a problem for judging again
+func (h *Handlers) rejudgeProblem(c *gin.Context) {
+	h.rejudge(c, "problemId", "invalid problem ID", h.judgeService.RejudgeProblem)
+}
+
+type rejudgeFunc func(ctx context.Context, id uuid.UUID, opts services.RejudgeOptions) (*services.RejudgeResult, error)
+
+// rejudge parses the ID parameter and the optional options body and runs fn
+func (h *Handlers) rejudge(c *gin.Context, param, invalidID string, fn rejudgeFunc) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidID})
+		return
+	}
+
+	var opts services.RejudgeOptions
+	if err := c.ShouldBindJSON(&opts); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := fn(c.Request.Context(), id, opts)
+	if errors.Is(err, services.ErrSubmissionNotFound) || errors.Is(err, services.ErrMatchNotFound) || errors.Is(err, services.ErrProblemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// getSubmissionHistory returns the outcomes a submission had before rejudges
+func (h *Handlers) getSubmissionHistory(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	history, err := h.judgeService.GetSubmissionHistory(submissionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -4,16 +4,26 @@ import (
 	"net/http"
 
 	"coderoulette/internal/services"
+	"coderoulette/pkg/limiter"
+	"coderoulette/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handlers struct {
-	matchService     *services.MatchService
-	problemService   *services.ProblemService
-	judgeService     *services.JudgeService
-	reportService    *services.ReportService
-	skillCardService *services.SkillCardService
+	matchService      *services.MatchService
+	problemService    *services.ProblemService
+	judgeService      *services.JudgeService
+	reportService     *services.ReportService
+	skillCardService  *services.SkillCardService
+	plagiarismService *services.PlagiarismService
+
+	adminToken string // bearer token of the admin routes, empty disables them
+
+	// Remote judge nodes, nil when the judge service is used in process
+	judgeClient *services.JudgeClient
+
+	runLimiter *limiter.Limiter // custom input runs per client and match, nil for unlimited
 }
 
 func NewHandlers(
@@ -22,16 +32,30 @@ func NewHandlers(
 	judgeService *services.JudgeService,
 	reportService *services.ReportService,
 	skillCardService *services.SkillCardService,
+	plagiarismService *services.PlagiarismService,
 ) *Handlers {
 	return &Handlers{
-		matchService:     matchService,
-		problemService:   problemService,
-		judgeService:     judgeService,
-		reportService:    reportService,
-		skillCardService: skillCardService,
+		matchService:      matchService,
+		problemService:    problemService,
+		judgeService:      judgeService,
+		reportService:     reportService,
+		skillCardService:  skillCardService,
+		plagiarismService: plagiarismService,
 	}
 }
 
+func (h *Handlers) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+func (h *Handlers) SetJudgeClient(client *services.JudgeClient) {
+	h.judgeClient = client
+}
+
+func (h *Handlers) SetRunLimiter(runLimiter *limiter.Limiter) {
+	h.runLimiter = runLimiter
+}
+
 func (h *Handlers) SetupRoutes(router *gin.Engine) {
 	// API routes
 	api := router.Group("/api/v1")
@@ -39,12 +63,17 @@ func (h *Handlers) SetupRoutes(router *gin.Engine) {
 		// Health check
 		api.GET("/health", h.healthCheck)
 
+		// Language routes
+		api.GET("/languages", h.getLanguages)
+
 		// Match routes
 		matches := api.Group("/matches")
 		{
 			matches.POST("/queue", h.queueForMatch)
 			matches.GET("/status/:id", h.getMatchStatus)
 			matches.GET("/queue-status", h.getQueueStatus)
+			matches.POST("/:id/ready", h.readyMatch)
+			matches.POST("/:id/forfeit", h.forfeitMatch)
 		}
 
 		// Problem routes
@@ -53,14 +82,15 @@ func (h *Handlers) SetupRoutes(router *gin.Engine) {
 			problems.GET("/random", h.getRandomProblem)
 			problems.GET("/:id", h.getProblem)
 			problems.GET("/", h.getProblems)
-			problems.POST("/", h.createProblem)
 		}
 
 		// Submission routes
 		submissions := api.Group("/submissions")
 		{
 			submissions.POST("/", h.submitCode)
+			submissions.POST("/run", h.runCode)
 			submissions.GET("/:id", h.getSubmission)
+			submissions.GET("/:id/stream", h.streamSubmission)
 			submissions.GET("/match/:matchId", h.getMatchSubmissions)
 		}
 
@@ -70,6 +100,7 @@ func (h *Handlers) SetupRoutes(router *gin.Engine) {
 			reports.GET("/:matchId", h.getReport)
 			reports.GET("/user/:userId", h.getUserReports)
 			reports.GET("/leaderboard", h.getLeaderboard)
+			reports.GET("/user/:userId/ratings", h.getRatingHistory)
 		}
 
 		// Skill card routes
@@ -79,6 +110,25 @@ func (h *Handlers) SetupRoutes(router *gin.Engine) {
 			skillCards.GET("/player/:playerId", h.getPlayerCards)
 			skillCards.POST("/use", h.useSkillCard)
 		}
+
+		// Admin routes
+		admin := api.Group("/admin", middleware.AdminToken(h.adminToken))
+		{
+			admin.POST("/rejudge/submissions/:id", h.rejudgeSubmission)
+			admin.POST("/rejudge/matches/:matchId", h.rejudgeMatch)
+			admin.POST("/rejudge/problems/:problemId", h.rejudgeProblem)
+			admin.GET("/submissions/:id/history", h.getSubmissionHistory)
+
+			admin.POST("/problems", h.createProblem)
+			admin.PUT("/problems/:id", h.updateProblem)
+			admin.POST("/problems/:id/stress", h.stressProblem)
+
+			admin.GET("/plagiarism/flags", h.getSimilarityFlags)
+			admin.GET("/plagiarism/flags/:id", h.getSimilarityFlag)
+			admin.PUT("/plagiarism/flags/:id", h.reviewSimilarityFlag)
+			admin.POST("/plagiarism/scan/submissions/:id", h.scanSubmission)
+			admin.POST("/plagiarism/scan/problems/:problemId", h.scanProblem)
+		}
 	}
 
 	// WebSocket routes
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getLanguages returns the languages the judge accepts
+func (h *Handlers) getLanguages(c *gin.Context) {
+	if h.judgeClient == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"languages": h.judgeService.Languages().List(),
+		})
+		return
+	}
+
+	languages, err := h.judgeClient.Languages(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"languages": languages})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"coderoulette/internal/services"
@@ -31,16 +33,49 @@ func (h *Handlers) queueForMatch(c *gin.Context) {
 		req.Language = "go"
 	}
 
-	// Add user to queue
+	if !h.judgeService.Languages().Has(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported language: " + req.Language})
+		return
+	}
+
+	// A waiting user may have been paired by their opponent's request
 	ctx := c.Request.Context()
-	if err := h.matchService.QueueUser(ctx, &req); err != nil {
+	result, err := h.matchService.PairedMatch(ctx, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if result != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "matched",
+			"match":  result,
+		})
+		return
+	}
+
+	// Add user to queue
+	err = h.matchService.QueueUser(ctx, &req)
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrProblemNotFound):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no problem available for this difficulty and language"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Try to find a match
-	result, err := h.matchService.FindMatch(ctx, &req)
-	if err != nil {
+	result, err = h.matchService.FindMatch(ctx, &req)
+	if errors.Is(err, services.ErrProblemNotFound) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no problem available for this difficulty and language"})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -80,21 +115,65 @@ func (h *Handlers) getMatchStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, match)
 }
 
-// getQueueStatus returns the number of users waiting in queue
+type MatchPlayerRequest struct {
+	PlayerID uuid.UUID `json:"player_id" binding:"required"`
+}
+
+// readyMatch confirms a player is ready, starting the countdown once both are
+func (h *Handlers) readyMatch(c *gin.Context) {
+	h.changeMatch(c, h.matchService.ReadyMatch)
+}
+
+// forfeitMatch lets a player leave a match
+func (h *Handlers) forfeitMatch(c *gin.Context) {
+	h.changeMatch(c, h.matchService.ForfeitMatch)
+}
+
+// changeMatch applies a player's action to a match and returns the match
+func (h *Handlers) changeMatch(c *gin.Context, action func(ctx context.Context, matchID, playerID uuid.UUID) (*services.MatchData, error)) {
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid match ID"})
+		return
+	}
+
+	var req MatchPlayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := action(c.Request.Context(), matchID, req.PlayerID)
+	switch {
+	case errors.Is(err, services.ErrMatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrNotInMatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// getQueueStatus returns the number of users waiting in queue and the
+// expected wait
 func (h *Handlers) getQueueStatus(c *gin.Context) {
 	difficulty := c.DefaultQuery("difficulty", "medium")
 	language := c.DefaultQuery("language", "go")
 
 	ctx := c.Request.Context()
-	count, err := h.matchService.GetQueueStatus(ctx, difficulty, language)
+	status, err := h.matchService.GetQueueStatus(ctx, difficulty, language)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"difficulty": difficulty,
-		"language":   language,
-		"queue_size": count,
-	})
+	c.JSON(http.StatusOK, status)
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"coderoulette/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReviewFlagRequest struct {
+	Status string `json:"status" binding:"required"` // open, confirmed, dismissed
+	Note   string `json:"note"`
+}
+
+// getSimilarityFlags returns flagged pairs with pagination, most similar first
+func (h *Handlers) getSimilarityFlags(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var problemID *uuid.UUID
+	if param := c.Query("problem_id"); param != "" {
+		id, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid problem ID"})
+			return
+		}
+		problemID = &id
+	}
+
+	flags, total, err := h.plagiarismService.ListFlags(page, limit, status, problemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flags": flags,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// getSimilarityFlag returns a flagged pair with the code of both sides
+func (h *Handlers) getSimilarityFlag(c *gin.Context) {
+	flagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flag ID"})
+		return
+	}
+
+	flag, err := h.plagiarismService.GetFlag(flagID)
+	if errors.Is(err, services.ErrFlagNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// reviewSimilarityFlag records a moderator's decision on a flagged pair
+func (h *Handlers) reviewSimilarityFlag(c *gin.Context) {
+	flagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flag ID"})
+		return
+	}
+
+	var req ReviewFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := h.plagiarismService.ReviewFlag(flagID, req.Status, req.Note)
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrFlagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// scanSubmission compares a submission with earlier ones right away
+func (h *Handlers) scanSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	flags, err := h.plagiarismService.ScanSubmission(submissionID)
+	if errors.Is(err, services.ErrSubmissionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// scanProblem compares all recent submissions to a problem with each other
+func (h *Handlers) scanProblem(c *gin.Context) {
+	problemID, err := uuid.Parse(c.Param("problemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid problem ID"})
+		return
+	}
+
+	flagged, err := h.plagiarismService.ScanProblem(problemID)
+	if errors.Is(err, services.ErrProblemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "flagged": flagged})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flagged": flagged})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -21,7 +23,7 @@ func (h *Handlers) getRandomProblem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, problem)
+	c.JSON(http.StatusOK, problem.Public())
 }
 
 // getProblem returns a specific problem by ID
@@ -39,7 +41,7 @@ func (h *Handlers) getProblem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, problem)
+	c.JSON(http.StatusOK, problem.Public())
 }
 
 // getProblems returns a list of problems with pagination
@@ -62,6 +64,10 @@ func (h *Handlers) getProblems(c *gin.Context) {
 		return
 	}
 
+	for i, problem := range problems {
+		problems[i] = problem.Public()
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"problems": problems,
 		"total":    total,
@@ -70,7 +76,7 @@ func (h *Handlers) getProblems(c *gin.Context) {
 	})
 }
 
-// createProblem creates a new problem
+// createProblem creates a new problem once its solutions pass validation
 func (h *Handlers) createProblem(c *gin.Context) {
 	var problem services.ProblemData
 	if err := c.ShouldBindJSON(&problem); err != nil {
@@ -78,10 +84,58 @@ func (h *Handlers) createProblem(c *gin.Context) {
 		return
 	}
 
+	if !h.checkProblem(c, &problem) {
+		return
+	}
+
+	if err := h.problemService.CreateProblem(&problem); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, problem)
+}
+
+// updateProblem replaces a problem once its solutions pass validation
+func (h *Handlers) updateProblem(c *gin.Context) {
+	problemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid problem ID"})
+		return
+	}
+
+	var problem services.ProblemData
+	if err := c.ShouldBindJSON(&problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.checkProblem(c, &problem) {
+		return
+	}
+
+	err = h.problemService.UpdateProblem(problemID, &problem)
+	if errors.Is(err, services.ErrProblemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "problem not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, problem)
+}
+
+// checkProblem fills in defaults, validates the problem's settings and runs
+// its solutions through the judge. Problems that fail validation are
+// rejected unless ?save_invalid=true, which stores them flagged as invalid.
+// It writes the error response and returns false if the problem must not
+// be saved.
+func (h *Handlers) checkProblem(c *gin.Context, problem *services.ProblemData) bool {
 	// Validate required fields
 	if problem.Title == "" || problem.Description == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "title and description are required"})
-		return
+		return false
 	}
 
 	if problem.Difficulty == "" {
@@ -92,10 +146,169 @@ func (h *Handlers) createProblem(c *gin.Context) {
 		problem.Language = "go"
 	}
 
-	if err := h.problemService.CreateProblem(&problem); err != nil {
+	if !h.judgeService.Languages().Has(problem.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported language: " + problem.Language})
+		return false
+	}
+
+	if err := problem.ValidateSignature(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid signature: " + err.Error()})
+		return false
+	}
+
+	if err := problem.ValidateType(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if problem.IsInteractive() && !h.judgeService.Languages().Has(problem.Interactor.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported interactor language: " + problem.Interactor.Language})
+		return false
+	}
+
+	if problem.Checker != nil {
+		if err := problem.Checker.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checker: " + err.Error()})
+			return false
+		}
+		if problem.Checker.Mode == services.CompareCustom && !h.judgeService.Languages().Has(problem.Checker.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported checker language: " + problem.Checker.Language})
+			return false
+		}
+	}
+
+	if err := problem.ValidateWrongSolutions(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if problem.Signature != nil && !services.HasHarness(problem.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "function problems are not available in " + problem.Language})
+		return false
+	}
+	for _, solution := range problem.WrongSolutions {
+		if !h.judgeService.Languages().Has(solution.Language) ||
+			problem.Signature != nil && !services.HasHarness(solution.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported wrong solution language: " + solution.Language})
+			return false
+		}
+	}
+
+	if err := problem.ValidateGenerators(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid generators: " + err.Error()})
+		return false
+	}
+	for _, generator := range problem.Generators {
+		if !h.judgeService.Languages().Has(generator.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported generator language: " + generator.Language})
+			return false
+		}
+	}
+	if err := h.generateTests(c.Request.Context(), problem); errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "generating tests: " + err.Error()})
+		return false
+	}
+
+	// Subtasks may consist of generated tests only
+	if err := problem.ValidateSubtasks(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subtasks: " + err.Error()})
+		return false
+	}
+
+	report, err := h.validateProblem(c.Request.Context(), problem)
+	if errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "validating problem: " + err.Error()})
+		return false
+	}
+	problem.Validation = report
+	switch {
+	case report == nil:
+		problem.ValidationStatus = services.ValidationPending
+	case report.Valid:
+		problem.ValidationStatus = services.ValidationPassed
+	default:
+		problem.ValidationStatus = services.ValidationFailed
+		if c.Query("save_invalid") != "true" {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":      "problem failed validation",
+				"validation": report,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// generateTests replaces the problem's generated tests, on the judge nodes
+// when they are deployed apart
+func (h *Handlers) generateTests(ctx context.Context, problem *services.ProblemData) error {
+	if h.judgeClient != nil {
+		return h.judgeClient.GenerateTests(ctx, problem)
+	}
+	return h.judgeService.GenerateTests(ctx, problem)
+}
+
+// validateProblem runs the problem's solutions, on the judge nodes when
+// they are deployed apart
+func (h *Handlers) validateProblem(ctx context.Context, problem *services.ProblemData) (*services.ValidationReport, error) {
+	if h.judgeClient != nil {
+		return h.judgeClient.ValidateProblem(ctx, problem)
+	}
+	return h.judgeService.ValidateProblem(ctx, problem)
+}
+
+// StressRequest asks to compare a solution against a problem's reference
+type StressRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Language string `json:"language" binding:"required"`
+	services.StressOptions
+}
+
+// stressProblem runs a candidate solution against the reference solution
+// on generated inputs and reports the first counterexample
+func (h *Handlers) stressProblem(c *gin.Context) {
+	problemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid problem ID"})
+		return
+	}
+
+	var req StressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	problem, err := h.problemService.GetProblemByID(problemID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "problem not found"})
+		return
+	}
+
+	result, err := h.stressTest(c.Request.Context(), problem, &req)
+	if errors.Is(err, services.ErrUnsupportedLanguage) || errors.Is(err, services.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, problem)
+	c.JSON(http.StatusOK, result)
+}
+
+// stressTest compares a solution with the problem's reference, on the judge
+// nodes when they are deployed apart
+func (h *Handlers) stressTest(ctx context.Context, problem *services.ProblemData, req *StressRequest) (*services.StressResult, error) {
+	if h.judgeClient != nil {
+		return h.judgeClient.StressTest(ctx, problem, req.Code, req.Language, req.StressOptions)
+	}
+	return h.judgeService.StressTest(ctx, problem, req.Code, req.Language, req.StressOptions)
 }
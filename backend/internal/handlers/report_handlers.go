@@ -77,3 +77,25 @@ func (h *Handlers) getLeaderboard(c *gin.Context) {
 		"leaderboard": leaderboard,
 	})
 }
+
+// getRatingHistory returns a user's rating changes, most recent first
+func (h *Handlers) getRatingHistory(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	history, err := h.reportService.GetRatingHistory(userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
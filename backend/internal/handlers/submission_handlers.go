@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"coderoulette/internal/mq"
 	"coderoulette/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -10,14 +17,26 @@ import (
 )
 
 type SubmitCodeRequest struct {
-	MatchID   uuid.UUID           `json:"match_id" binding:"required"`
-	PlayerID  uuid.UUID           `json:"player_id" binding:"required"`
-	Code      string              `json:"code" binding:"required"`
-	Language  string              `json:"language" binding:"required"`
-	TestCases []services.TestCase `json:"test_cases" binding:"required"`
+	MatchID  uuid.UUID `json:"match_id" binding:"required"`
+	PlayerID uuid.UUID `json:"player_id" binding:"required"`
+	Code     string    `json:"code" binding:"required"`
+	Language string    `json:"language" binding:"required"`
+	Mode     string    `json:"mode"` // run_all (default) or stop_on_failure
 }
 
-// submitCode handles code submission for judging
+type RunCodeRequest struct {
+	MatchID  uuid.UUID `json:"match_id"`
+	PlayerID uuid.UUID `json:"player_id" binding:"required"` // must be in the match
+	Code     string    `json:"code" binding:"required"`
+	Language string    `json:"language" binding:"required"`
+	Input    string    `json:"input"`
+
+	// Runs are rate limited by client address and match, the player ID
+	// is the client's say
+	ClientIP string `json:"-"`
+}
+
+// submitCode queues code for judging and returns the pending submission
 func (h *Handlers) submitCode(c *gin.Context) {
 	var req SubmitCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -35,15 +54,64 @@ func (h *Handlers) submitCode(c *gin.Context) {
 		req.Language = "go"
 	}
 
-	if len(req.TestCases) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "test cases are required"})
+	// Queue code for judging
+	ctx := c.Request.Context()
+	var submission *services.SubmissionData
+	var err error
+	if h.judgeClient != nil {
+		submission, err = h.judgeClient.SubmitCode(ctx, req.MatchID, req.PlayerID, req.Code, req.Language, req.Mode)
+	} else {
+		submission, err = h.judgeService.SubmitCode(ctx, req.MatchID, req.PlayerID, req.Code, req.Language, req.Mode)
+	}
+	if errors.Is(err, services.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrMatchNotActive) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrNotInMatch) || errors.Is(err, services.ErrNoProblem) ||
+		errors.Is(err, services.ErrUnsupportedLanguage) || errors.Is(err, services.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, mq.ErrQueueFull) || errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "judge is busy, try again shortly"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Submit code for judging
-	ctx := c.Request.Context()
-	result, err := h.judgeService.SubmitCode(ctx, req.MatchID, req.PlayerID, req.Code, req.Language, req.TestCases)
-	if err != nil {
+	c.JSON(http.StatusAccepted, submission)
+}
+
+// runCode runs code on custom input without creating a scored submission
+func (h *Handlers) runCode(c *gin.Context) {
+	var req RunCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	result, err := h.runCustomInput(c.Request.Context(), &req)
+	var limited *runLimitError
+	if errors.As(err, &limited) {
+		c.Header("Retry-After", strconv.Itoa(int(limited.retryAfter.Seconds()+0.999)))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrNotInMatch) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrUnsupportedLanguage) || errors.Is(err, services.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "judge is busy, try again shortly"})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -51,7 +119,35 @@ func (h *Handlers) submitCode(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// getSubmission returns a submission by ID
+// runLimitError is returned when a player used up their runs for now
+type runLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *runLimitError) Error() string {
+	return fmt.Sprintf("too many runs, try again in %s", e.retryAfter.Round(time.Second))
+}
+
+// runCustomInput runs code on custom input, on the judge nodes when they
+// are deployed apart, once the client's run budget allows it
+func (h *Handlers) runCustomInput(ctx context.Context, req *RunCodeRequest) (*services.RunResult, error) {
+	if h.runLimiter != nil {
+		allowed, retryAfter, err := h.runLimiter.Allow(ctx, req.ClientIP+":"+req.MatchID.String())
+		if err != nil {
+			// Players keep testing their code while Redis is down
+			log.Printf("Run rate limiter: %v", err)
+		} else if !allowed {
+			return nil, &runLimitError{retryAfter: retryAfter}
+		}
+	}
+
+	if h.judgeClient != nil {
+		return h.judgeClient.RunCustomInput(ctx, req.MatchID, req.PlayerID, req.Code, req.Language, req.Input)
+	}
+	return h.judgeService.RunCustomInput(ctx, req.MatchID, req.PlayerID, req.Code, req.Language, req.Input)
+}
+
+// getSubmission returns a submission by ID, including its judging progress
 func (h *Handlers) getSubmission(c *gin.Context) {
 	submissionIDStr := c.Param("id")
 	submissionID, err := uuid.Parse(submissionIDStr)
@@ -60,8 +156,16 @@ func (h *Handlers) getSubmission(c *gin.Context) {
 		return
 	}
 
-	submission, err := h.judgeService.GetSubmission(submissionID)
-	if err != nil {
+	var submission *services.SubmissionData
+	if h.judgeClient != nil {
+		submission, err = h.judgeClient.GetSubmission(c.Request.Context(), submissionID)
+	} else {
+		submission, err = h.judgeService.GetSubmission(submissionID)
+	}
+	if errors.Is(err, services.ErrJudgeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
 		return
 	}
@@ -69,6 +173,34 @@ func (h *Handlers) getSubmission(c *gin.Context) {
 	c.JSON(http.StatusOK, submission)
 }
 
+// streamSubmission sends the submission as server-sent events whenever its
+// progress changes, until it is judged
+func (h *Handlers) streamSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	watch := h.judgeService.WatchSubmission
+	if h.judgeClient != nil {
+		watch = h.judgeClient.WatchSubmission
+	}
+
+	sent := false
+	err = watch(c.Request.Context(), submissionID, func(submission *services.SubmissionData) error {
+		c.SSEvent("submission", submission)
+		c.Writer.Flush()
+		sent = true
+		return nil
+	})
+	if err != nil && !sent {
+		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+	} else if err != nil && c.Request.Context().Err() == nil {
+		c.SSEvent("error", gin.H{"error": err.Error()})
+	}
+}
+
 // getMatchSubmissions returns all submissions for a match
 func (h *Handlers) getMatchSubmissions(c *gin.Context) {
 	matchIDStr := c.Param("matchId")
@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"sync"
+
+	"coderoulette/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -22,6 +30,19 @@ type WebSocketMessage struct {
 	Timestamp int64       `json:"timestamp"`
 }
 
+// wsConn serializes writes to a connection, which the room's events and the
+// replies to its messages share
+type wsConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *wsConn) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.WriteJSON(v)
+}
+
 // handleWebSocket handles WebSocket connections for real-time match communication
 func (h *Handlers) handleWebSocket(c *gin.Context) {
 	roomID := c.Param("roomId")
@@ -31,15 +52,22 @@ func (h *Handlers) handleWebSocket(c *gin.Context) {
 	}
 
 	// Upgrade HTTP connection to WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
-	defer conn.Close()
+	defer ws.Close()
+	conn := &wsConn{Conn: ws}
 
 	log.Printf("WebSocket connection established for room: %s", roomID)
 
+	// Relay what the server announces to the room, such as the state
+	// changes made by the match timers
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go relayRoomEvents(conn, h.matchService.RoomEvents(ctx, roomID))
+
 	// Handle WebSocket messages
 	for {
 		var msg WebSocketMessage
@@ -52,9 +80,11 @@ func (h *Handlers) handleWebSocket(c *gin.Context) {
 		// Process message based on type
 		switch msg.Type {
 		case "join_room":
-			h.handleJoinRoom(conn, roomID, &msg)
+			h.handleJoinRoom(c.Request.Context(), conn, roomID, &msg)
 		case "code_submission":
 			h.handleCodeSubmission(conn, roomID, &msg)
+		case "run_code":
+			h.handleRunCode(c.Request.Context(), conn, c.ClientIP(), &msg)
 		case "skill_card_use":
 			h.handleSkillCardUse(conn, roomID, &msg)
 		case "ping":
@@ -65,8 +95,38 @@ func (h *Handlers) handleWebSocket(c *gin.Context) {
 	}
 }
 
-// handleJoinRoom handles when a player joins a match room
-func (h *Handlers) handleJoinRoom(conn *websocket.Conn, roomID string, msg *WebSocketMessage) {
+// relayRoomEvents writes a room's events to a connection as messages of the
+// event's type, match_start carrying the match as when joining
+func relayRoomEvents(conn *wsConn, events <-chan []byte) {
+	for payload := range events {
+		var event struct {
+			Type    string          `json:"type"`
+			MatchID string          `json:"match_id"`
+			Match   json.RawMessage `json:"match"`
+		}
+		if err := json.Unmarshal(payload, &event); err != nil {
+			log.Printf("Invalid room event: %v", err)
+			continue
+		}
+
+		data := json.RawMessage(payload)
+		if len(event.Match) > 0 {
+			data = event.Match
+		}
+		if err := conn.WriteJSON(WebSocketMessage{
+			Type:      event.Type,
+			Data:      data,
+			MatchID:   event.MatchID,
+			Timestamp: getCurrentTimestamp(),
+		}); err != nil {
+			log.Printf("WebSocket write error: %v", err)
+		}
+	}
+}
+
+// handleJoinRoom handles when a player joins a match room, sending the
+// match's start event with its problem once joined
+func (h *Handlers) handleJoinRoom(ctx context.Context, conn *wsConn, roomID string, msg *WebSocketMessage) {
 	response := WebSocketMessage{
 		Type:      "room_joined",
 		Data:      map[string]string{"room_id": roomID, "status": "success"},
@@ -77,11 +137,35 @@ func (h *Handlers) handleJoinRoom(conn *websocket.Conn, roomID string, msg *WebS
 		log.Printf("WebSocket write error: %v", err)
 	}
 
+	match, err := h.matchService.RoomMatch(ctx, roomID)
+	if err != nil {
+		if !errors.Is(err, services.ErrMatchNotFound) {
+			log.Printf("Failed to load match of room %s: %v", roomID, err)
+		}
+	} else {
+		// A player joining starts the ready check
+		if playerID, err := uuid.Parse(msg.PlayerID); err == nil {
+			if _, err := h.matchService.JoinMatch(ctx, match.MatchID, playerID); err != nil && !errors.Is(err, services.ErrNotInMatch) {
+				log.Printf("Failed to join match %s: %v", match.MatchID, err)
+			}
+		}
+
+		start := WebSocketMessage{
+			Type:      "match_start",
+			Data:      match,
+			MatchID:   match.MatchID.String(),
+			Timestamp: getCurrentTimestamp(),
+		}
+		if err := conn.WriteJSON(start); err != nil {
+			log.Printf("WebSocket write error: %v", err)
+		}
+	}
+
 	log.Printf("Player %s joined room %s", msg.PlayerID, roomID)
 }
 
 // handleCodeSubmission handles code submission via WebSocket
-func (h *Handlers) handleCodeSubmission(conn *websocket.Conn, roomID string, msg *WebSocketMessage) {
+func (h *Handlers) handleCodeSubmission(conn *wsConn, roomID string, msg *WebSocketMessage) {
 	// Broadcast submission to other players in the room
 	// broadcastMsg := WebSocketMessage{
 	// 	Type:      "code_submitted",
@@ -106,8 +190,60 @@ func (h *Handlers) handleCodeSubmission(conn *websocket.Conn, roomID string, msg
 	log.Printf("Code submission received from player %s in room %s", msg.PlayerID, roomID)
 }
 
+// handleRunCode runs code on custom input for the sender only, the same way
+// as the run endpoint; data holds code, language and input
+func (h *Handlers) handleRunCode(ctx context.Context, conn *wsConn, clientIP string, msg *WebSocketMessage) {
+	req, err := runCodeMessage(msg)
+	var result *services.RunResult
+	if err == nil {
+		req.ClientIP = clientIP
+		result, err = h.runCustomInput(ctx, req)
+	}
+
+	response := WebSocketMessage{
+		Type:      "run_result",
+		Data:      result,
+		PlayerID:  msg.PlayerID,
+		MatchID:   msg.MatchID,
+		Timestamp: getCurrentTimestamp(),
+	}
+	if err != nil {
+		response.Type = "run_error"
+		response.Data = map[string]string{"error": err.Error()}
+	}
+
+	if err := conn.WriteJSON(response); err != nil {
+		log.Printf("WebSocket write error: %v", err)
+	}
+}
+
+// runCodeMessage reads a run request from a run_code message
+func runCodeMessage(msg *WebSocketMessage) (*RunCodeRequest, error) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	var req RunCodeRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid run_code data: %w", err)
+	}
+
+	if req.PlayerID, err = uuid.Parse(msg.PlayerID); err != nil {
+		return nil, errors.New("invalid player ID")
+	}
+	if msg.MatchID != "" {
+		if req.MatchID, err = uuid.Parse(msg.MatchID); err != nil {
+			return nil, errors.New("invalid match ID")
+		}
+	}
+	if req.Code == "" || req.Language == "" {
+		return nil, errors.New("code and language are required")
+	}
+	return &req, nil
+}
+
 // handleSkillCardUse handles skill card usage via WebSocket
-func (h *Handlers) handleSkillCardUse(conn *websocket.Conn, roomID string, msg *WebSocketMessage) {
+func (h *Handlers) handleSkillCardUse(conn *wsConn, roomID string, msg *WebSocketMessage) {
 	// Broadcast skill card usage to other players
 	// broadcastMsg := WebSocketMessage{
 	// 	Type:      "skill_card_used",
@@ -132,7 +268,7 @@ func (h *Handlers) handleSkillCardUse(conn *websocket.Conn, roomID string, msg *
 }
 
 // handlePing handles ping messages for connection health
-func (h *Handlers) handlePing(conn *websocket.Conn, msg *WebSocketMessage) {
+func (h *Handlers) handlePing(conn *wsConn, msg *WebSocketMessage) {
 	response := WebSocketMessage{
 		Type:      "pong",
 		Data:      map[string]string{"status": "ok"},
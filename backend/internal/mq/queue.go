@@ -0,0 +1,171 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrQueueFull is returned by Publish when the backlog reached its limit
+var ErrQueueFull = errors.New("queue is full")
+
+// payloadField is the stream entry field holding the message body
+const payloadField = "payload"
+
+// Queue is a work queue on a Redis stream with a single consumer group.
+// Messages stay pending until acknowledged, so a consumer that dies
+// mid-job has its messages reclaimed by another consumer after ClaimIdle.
+// Consumers of long jobs call Refresh to keep their messages.
+type Queue struct {
+	redis     *redis.Client
+	stream    string
+	group     string
+	maxLength int64         // 0 means unbounded
+	claimIdle time.Duration // how long a message may stay unacknowledged
+}
+
+// Message is a single queued job
+type Message struct {
+	ID      string
+	Payload []byte
+
+	// Deliveries counts how often the message was handed to a consumer,
+	// including this time
+	Deliveries int64
+}
+
+func NewQueue(redis *redis.Client, stream string, maxLength int64, claimIdle time.Duration) *Queue {
+	return &Queue{
+		redis:     redis,
+		stream:    stream,
+		group:     stream + ":workers",
+		maxLength: maxLength,
+		claimIdle: claimIdle,
+	}
+}
+
+// Init creates the stream and consumer group if they do not exist yet
+func (q *Queue) Init(ctx context.Context) error {
+	err := q.redis.XGroupCreateMkStream(ctx, q.stream, q.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// Publish appends a message to the queue
+func (q *Queue) Publish(ctx context.Context, payload []byte) error {
+	if q.maxLength > 0 {
+		length, err := q.Len(ctx)
+		if err != nil {
+			return err
+		}
+		if length >= q.maxLength {
+			return ErrQueueFull
+		}
+	}
+
+	return q.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		Values: map[string]interface{}{payloadField: payload},
+	}).Err()
+}
+
+// Consume returns the next message for consumer, blocking up to block.
+// Stale messages abandoned by other consumers are handed out first.
+// It returns nil, nil when nothing arrived in time.
+func (q *Queue) Consume(ctx context.Context, consumer string, block time.Duration) (*Message, error) {
+	if q.claimIdle > 0 {
+		claimed, _, err := q.redis.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.stream,
+			Group:    q.group,
+			MinIdle:  q.claimIdle,
+			Start:    "0",
+			Count:    1,
+			Consumer: consumer,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(claimed) > 0 {
+			msg := newMessage(claimed[0])
+			if msg.Deliveries, err = q.deliveries(ctx, msg.ID); err != nil {
+				return nil, err
+			}
+			return msg, nil
+		}
+	}
+
+	streams, err := q.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.group,
+		Consumer: consumer,
+		Streams:  []string{q.stream, ">"},
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return nil, nil
+	}
+	return newMessage(streams[0].Messages[0]), nil
+}
+
+// Refresh tells other consumers that consumer is still working on a
+// message, so it is not reclaimed while the job runs longer than ClaimIdle
+func (q *Queue) Refresh(ctx context.Context, consumer, id string) error {
+	return q.redis.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream:   q.stream,
+		Group:    q.group,
+		Consumer: consumer,
+		Messages: []string{id},
+	}).Err()
+}
+
+// RefreshInterval is how often Refresh must be called to keep a message
+func (q *Queue) RefreshInterval() time.Duration {
+	return q.claimIdle / 3
+}
+
+// Ack marks a message as processed and removes it from the stream
+func (q *Queue) Ack(ctx context.Context, id string) error {
+	if err := q.redis.XAck(ctx, q.stream, q.group, id).Err(); err != nil {
+		return err
+	}
+	return q.redis.XDel(ctx, q.stream, id).Err()
+}
+
+// Len returns the number of messages waiting or in progress
+func (q *Queue) Len(ctx context.Context) (int64, error) {
+	return q.redis.XLen(ctx, q.stream).Result()
+}
+
+// deliveries returns how often a pending message was delivered
+func (q *Queue) deliveries(ctx context.Context, id string) (int64, error) {
+	pending, err := q.redis.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: q.stream,
+		Group:  q.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 1, nil
+	}
+	return pending[0].RetryCount, nil
+}
+
+func newMessage(msg redis.XMessage) *Message {
+	payload, _ := msg.Values[payloadField].(string)
+	return &Message{ID: msg.ID, Payload: []byte(payload), Deliveries: 1}
+}
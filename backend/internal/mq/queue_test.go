@@ -0,0 +1,82 @@
+package mq
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// testQueue returns a queue on the Redis in TEST_REDIS_URL, its database
+// is flushed
+func testQueue(t *testing.T, claimIdle time.Duration) *Queue {
+	t.Helper()
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set")
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("parse TEST_REDIS_URL: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() { client.Close() })
+	if err := client.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("flush redis: %v", err)
+	}
+
+	queue := NewQueue(client, "test", 0, claimIdle)
+	if err := queue.Init(context.Background()); err != nil {
+		t.Fatalf("init queue: %v", err)
+	}
+	return queue
+}
+
+func TestConsumeCountsDeliveries(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue(t, 50*time.Millisecond)
+	if err := queue.Publish(ctx, []byte("job")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for want := int64(1); want <= 3; want++ {
+		msg, err := queue.Consume(ctx, "worker", 0)
+		if err != nil || msg == nil {
+			t.Fatalf("consume = %v, %v, want a message", msg, err)
+		}
+		if msg.Deliveries != want || string(msg.Payload) != "job" {
+			t.Errorf("delivery %d: got %q delivered %d times", want, msg.Payload, msg.Deliveries)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestRefreshKeepsMessage(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue(t, 200*time.Millisecond)
+	if err := queue.Publish(ctx, []byte("job")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	msg, err := queue.Consume(ctx, "first", 0)
+	if err != nil || msg == nil {
+		t.Fatalf("consume = %v, %v, want a message", msg, err)
+	}
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if err := queue.Refresh(ctx, "first", msg.ID); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+		if other, err := queue.Consume(ctx, "second", 10*time.Millisecond); err != nil || other != nil {
+			t.Fatalf("consume of a refreshed message = %v, %v, want nothing", other, err)
+		}
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	other, err := queue.Consume(ctx, "second", 0)
+	if err != nil || other == nil || other.ID != msg.ID || other.Deliveries != 2 {
+		t.Fatalf("consume after the first consumer stopped = %+v, %v, want the message again", other, err)
+	}
+}
@@ -0,0 +1,167 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// WorkDir is the path the per-run working directory is mounted at inside the sandbox
+const WorkDir = "/sandbox"
+
+// DefaultCgroupRoot is the cgroup v2 directory under which every run gets its own cgroup
+const DefaultCgroupRoot = "/sys/fs/cgroup/coderoulette"
+
+// ErrUnsupported is returned on platforms without namespace and cgroup support
+var ErrUnsupported = errors.New("sandbox: unsupported platform")
+
+// Limits describes the resources a single sandboxed process may use
+type Limits struct {
+	TimeLimit     time.Duration // CPU time
+	WallTimeLimit time.Duration // real time, including time spent blocked
+	MemoryLimit   int64         // in bytes
+	MaxProcesses  int           // processes and threads
+	MaxOutputSize int64         // in bytes, stdout and stderr each
+}
+
+// DefaultLimits are used when a problem does not configure its own
+var DefaultLimits = Limits{
+	TimeLimit:     2 * time.Second,
+	WallTimeLimit: 5 * time.Second,
+	MemoryLimit:   256 << 20,
+	MaxProcesses:  64,
+	MaxOutputSize: 1 << 20,
+}
+
+// CompileLimits are used for compilation steps, which need more room than test runs
+var CompileLimits = Limits{
+	TimeLimit:     20 * time.Second,
+	WallTimeLimit: 30 * time.Second,
+	MemoryLimit:   1 << 30,
+	MaxProcesses:  512,
+	MaxOutputSize: 1 << 20,
+}
+
+// DefaultEnv is the environment used when a command does not set its own
+var DefaultEnv = []string{
+	"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+	"HOME=/tmp",
+	"LANG=C.UTF-8",
+}
+
+// Command describes a single program execution inside the sandbox
+type Command struct {
+	Args   []string  // argv, resolved inside the sandbox
+	Dir    string    // host directory mounted read-write at WorkDir
+	Env    []string  // complete environment, nothing is inherited
+	Stdin  io.Reader // optional
+	Stdout io.Writer // optional, gets stdout as it is written in addition to Result.Stdout
+	Limits Limits
+	Mounts []Mount // extra host directories, e.g. build caches
+}
+
+// Mount exposes a host directory inside the sandbox
+type Mount struct {
+	Source   string // host path
+	Target   string // path inside the sandbox
+	Writable bool   // write through to the host, only for trusted commands
+}
+
+// Result describes how a sandboxed process finished
+type Result struct {
+	Stdout              []byte         `json:"stdout"`
+	Stderr              []byte         `json:"stderr"`
+	ExitCode            int            `json:"exit_code"`
+	Signal              syscall.Signal `json:"signal"` // 0 if the process exited normally
+	TimeLimitExceeded   bool           `json:"time_limit_exceeded"`
+	MemoryLimitExceeded bool           `json:"memory_limit_exceeded"`
+	OutputLimitExceeded bool           `json:"output_limit_exceeded"`
+	WallTime            time.Duration  `json:"wall_time"`
+
+	// Resource usage of all processes of the run
+	CPUTime    time.Duration `json:"cpu_time"`    // user + system
+	PeakMemory int64         `json:"peak_memory"` // in bytes
+}
+
+// Succeeded reports whether the process exited with status 0 within its limits
+func (r *Result) Succeeded() bool {
+	return r.ExitCode == 0 && r.Signal == 0 &&
+		!r.TimeLimitExceeded && !r.MemoryLimitExceeded && !r.OutputLimitExceeded
+}
+
+// Sandbox runs untrusted programs isolated from the host and from each other.
+// Each run gets fresh namespaces (no network), a read-only view of the system
+// directories, a private tmpfs at /tmp and its own cgroup for memory and
+// process limits.
+type Sandbox struct {
+	CgroupRoot    string   // cgroup v2 directory delegated to the judge
+	BwrapPath     string   // bubblewrap binary used to build the namespaces
+	PrlimitPath   string   // prlimit binary used to apply rlimits inside the sandbox
+	ReadOnlyPaths []string // host paths exposed read-only, skipped if missing
+
+	rootOnce sync.Once
+	rootErr  error
+}
+
+// New creates a sandbox that places runs under the given cgroup root
+func New(cgroupRoot string) *Sandbox {
+	if cgroupRoot == "" {
+		cgroupRoot = DefaultCgroupRoot
+	}
+	return &Sandbox{
+		CgroupRoot:  cgroupRoot,
+		BwrapPath:   "bwrap",
+		PrlimitPath: "prlimit",
+		ReadOnlyPaths: []string{
+			"/usr", "/bin", "/sbin", "/lib", "/lib64", "/etc/alternatives", "/etc/ssl", "/opt",
+		},
+	}
+}
+
+// Run executes cmd inside the sandbox and waits for it to finish.
+// A non-nil error means the sandbox itself failed; limit violations and
+// non-zero exits of the program are reported through the Result.
+func (s *Sandbox) Run(ctx context.Context, cmd *Command) (*Result, error) {
+	return s.run(ctx, cmd)
+}
+
+// forwardWriter passes output on until the destination fails, after which
+// it drops it, so a peer closing its pipe early does not fail the run
+type forwardWriter struct {
+	w      io.Writer
+	failed bool
+}
+
+func (f *forwardWriter) Write(p []byte) (int, error) {
+	if !f.failed {
+		if _, err := f.w.Write(p); err != nil {
+			f.failed = true
+		}
+	}
+	return len(p), nil
+}
+
+// limitedBuffer collects output up to a limit and reports when it is exceeded
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+	onExceed func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded {
+		return len(p), nil
+	}
+	if remaining := b.limit - int64(b.buf.Len()); int64(len(p)) > remaining {
+		b.buf.Write(p[:remaining])
+		b.exceeded = true
+		b.onExceed()
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,301 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxFileSize caps any single file a sandboxed program writes
+const maxFileSize = 64 << 20
+
+func (s *Sandbox) run(ctx context.Context, cmd *Command) (*Result, error) {
+	if len(cmd.Args) == 0 {
+		return nil, errors.New("sandbox: empty command")
+	}
+
+	cgroupDir, err := s.createCgroup(cmd.Limits)
+	if err != nil {
+		return nil, err
+	}
+	defer removeCgroup(cgroupDir)
+
+	cgroupFD, err := syscall.Open(cgroupDir, syscall.O_DIRECTORY|syscall.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: open cgroup: %w", err)
+	}
+	defer syscall.Close(cgroupFD)
+
+	wallLimit := cmd.Limits.WallTimeLimit
+	if wallLimit <= 0 {
+		wallLimit = DefaultLimits.WallTimeLimit
+	}
+	runCtx, cancel := context.WithTimeout(ctx, wallLimit)
+	defer cancel()
+
+	maxOutput := cmd.Limits.MaxOutputSize
+	if maxOutput <= 0 {
+		maxOutput = DefaultLimits.MaxOutputSize
+	}
+	stdout := &limitedBuffer{limit: maxOutput, onExceed: cancel}
+	stderr := &limitedBuffer{limit: maxOutput, onExceed: cancel}
+
+	execCmd := exec.CommandContext(runCtx, s.BwrapPath, s.bwrapArgs(cmd)...)
+	execCmd.Env = cmd.Env
+	if execCmd.Env == nil {
+		execCmd.Env = DefaultEnv
+	}
+	execCmd.Stdin = cmd.Stdin
+	execCmd.Stdout = stdout
+	if cmd.Stdout != nil {
+		execCmd.Stdout = io.MultiWriter(&forwardWriter{w: cmd.Stdout}, stdout)
+	}
+	execCmd.Stderr = stderr
+	execCmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:     true,
+		Pdeathsig:   syscall.SIGKILL,
+		UseCgroupFD: true,
+		CgroupFD:    cgroupFD,
+	}
+	// Kill everything left in the cgroup, not just bwrap itself
+	execCmd.Cancel = func() error {
+		killCgroup(cgroupDir)
+		return execCmd.Process.Kill()
+	}
+
+	start := time.Now()
+	runErr := execCmd.Run()
+	result := &Result{
+		Stdout:              stdout.buf.Bytes(),
+		Stderr:              stderr.buf.Bytes(),
+		OutputLimitExceeded: stdout.exceeded || stderr.exceeded,
+		WallTime:            time.Since(start),
+	}
+
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, fmt.Errorf("sandbox: start: %w", runErr)
+	}
+
+	if status, ok := execCmd.ProcessState.Sys().(syscall.WaitStatus); ok {
+		switch {
+		case status.Signaled():
+			result.Signal = status.Signal()
+		default:
+			result.ExitCode = status.ExitStatus()
+			if signal, ok := exitSignal(result.ExitCode); ok {
+				result.Signal = signal
+				result.ExitCode = 0
+			}
+		}
+	}
+
+	result.CPUTime, result.PeakMemory = resourceUsage(cgroupDir, execCmd.ProcessState)
+
+	// RLIMIT_CPU only has second granularity, the exact limit is checked here
+	timeLimit := cmd.Limits.TimeLimit
+	if timeLimit <= 0 {
+		timeLimit = DefaultLimits.TimeLimit
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) || result.Signal == syscall.SIGXCPU || result.CPUTime > timeLimit {
+		result.TimeLimitExceeded = true
+	}
+	if oomKills(cgroupDir) > 0 {
+		result.MemoryLimitExceeded = true
+	}
+
+	return result, nil
+}
+
+// exitSignals are the signals a sandboxed program dies from, by faulting,
+// aborting or hitting a limit, or is killed with
+var exitSignals = map[syscall.Signal]bool{
+	syscall.SIGABRT: true,
+	syscall.SIGBUS:  true,
+	syscall.SIGFPE:  true,
+	syscall.SIGILL:  true,
+	syscall.SIGKILL: true,
+	syscall.SIGPIPE: true,
+	syscall.SIGSEGV: true,
+	syscall.SIGSYS:  true,
+	syscall.SIGTERM: true,
+	syscall.SIGTRAP: true,
+	syscall.SIGXCPU: true,
+	syscall.SIGXFSZ: true,
+}
+
+// exitSignal returns the signal bwrap reports a signalled child with, as
+// exit status 128+signal. Other statuses above 128 are the program's own,
+// such as exit(200).
+func exitSignal(status int) (syscall.Signal, bool) {
+	signal := syscall.Signal(status - 128)
+	return signal, status > 128 && exitSignals[signal]
+}
+
+// bwrapArgs builds the bubblewrap command line for cmd
+func (s *Sandbox) bwrapArgs(cmd *Command) []string {
+	args := []string{
+		"--unshare-all",
+		"--die-with-parent",
+		"--new-session",
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+	}
+	for _, path := range s.ReadOnlyPaths {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	args = append(args, "--bind", cmd.Dir, WorkDir)
+	for _, mount := range cmd.Mounts {
+		if mount.Writable {
+			args = append(args, "--bind", mount.Source, mount.Target)
+		} else {
+			// Writable for the program but changes are thrown away, so
+			// untrusted code can use a shared cache without altering it.
+			// Needs bubblewrap 0.8 or newer.
+			args = append(args, "--overlay-src", mount.Source, "--tmp-overlay", mount.Target)
+		}
+	}
+	args = append(args,
+		"--chdir", WorkDir,
+		"--remount-ro", "/",
+	)
+
+	// rlimits that cgroups cannot express
+	timeLimit := cmd.Limits.TimeLimit
+	if timeLimit <= 0 {
+		timeLimit = DefaultLimits.TimeLimit
+	}
+	cpuSeconds := int((timeLimit + time.Second - 1) / time.Second)
+	args = append(args, "--", s.PrlimitPath,
+		fmt.Sprintf("--cpu=%d:%d", cpuSeconds, cpuSeconds+1),
+		fmt.Sprintf("--fsize=%d", maxFileSize),
+		"--core=0",
+		"--",
+	)
+	return append(args, cmd.Args...)
+}
+
+// createCgroup creates a fresh leaf cgroup with memory and process limits
+func (s *Sandbox) createCgroup(limits Limits) (string, error) {
+	s.rootOnce.Do(func() {
+		if err := os.MkdirAll(s.CgroupRoot, 0755); err != nil {
+			s.rootErr = err
+			return
+		}
+		s.rootErr = writeCgroupFile(s.CgroupRoot, "cgroup.subtree_control", "+memory +pids +cpu")
+	})
+	if s.rootErr != nil {
+		return "", fmt.Errorf("sandbox: cgroup root %s: %w", s.CgroupRoot, s.rootErr)
+	}
+
+	dir := filepath.Join(s.CgroupRoot, "run-"+uuid.NewString())
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return "", fmt.Errorf("sandbox: create cgroup: %w", err)
+	}
+
+	memory := limits.MemoryLimit
+	if memory <= 0 {
+		memory = DefaultLimits.MemoryLimit
+	}
+	processes := limits.MaxProcesses
+	if processes <= 0 {
+		processes = DefaultLimits.MaxProcesses
+	}
+
+	settings := [][2]string{
+		{"memory.max", strconv.FormatInt(memory, 10)},
+		{"memory.swap.max", "0"},
+		{"pids.max", strconv.Itoa(processes)},
+	}
+	for _, setting := range settings {
+		if err := writeCgroupFile(dir, setting[0], setting[1]); err != nil {
+			removeCgroup(dir)
+			return "", fmt.Errorf("sandbox: configure cgroup: %w", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// killCgroup kills every process in the cgroup
+func killCgroup(dir string) {
+	writeCgroupFile(dir, "cgroup.kill", "1")
+}
+
+// removeCgroup kills any leftover processes and removes the cgroup
+func removeCgroup(dir string) {
+	killCgroup(dir)
+	for i := 0; i < 50; i++ {
+		if err := os.Remove(dir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// resourceUsage returns the CPU time and peak memory of a finished run.
+// The cgroup accounts for every process of the run; rusage of the reaped
+// bwrap process is the fallback on kernels without cpu.stat or memory.peak.
+func resourceUsage(dir string, state *os.ProcessState) (time.Duration, int64) {
+	var cpuTime time.Duration
+	var peakMemory int64
+
+	if usec, ok := readCgroupStat(dir, "cpu.stat", "usage_usec"); ok {
+		cpuTime = time.Duration(usec) * time.Microsecond
+	} else if state != nil {
+		cpuTime = state.UserTime() + state.SystemTime()
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "memory.peak")); err == nil {
+		peakMemory, _ = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	} else if state != nil {
+		if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+			peakMemory = rusage.Maxrss << 10 // Maxrss is in kilobytes
+		}
+	}
+
+	return cpuTime, peakMemory
+}
+
+// oomKills returns how many processes in the cgroup were killed by the OOM killer
+func oomKills(dir string) int {
+	count, _ := readCgroupStat(dir, "memory.events", "oom_kill")
+	return int(count)
+}
+
+// readCgroupStat reads one key of a flat keyed cgroup file such as cpu.stat
+func readCgroupStat(dir, name, key string) (int64, bool) {
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			return value, err == nil
+		}
+	}
+	return 0, false
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(value), 0644)
+}
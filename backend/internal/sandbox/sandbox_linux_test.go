@@ -0,0 +1,34 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestExitSignal(t *testing.T) {
+	tests := []struct {
+		status int
+		signal syscall.Signal
+		ok     bool
+	}{
+		{0, 0, false},
+		{1, 0, false},
+		{128, 0, false},
+		{128 + 6, syscall.SIGABRT, true},
+		{128 + 9, syscall.SIGKILL, true},
+		{128 + 11, syscall.SIGSEGV, true},
+		{128 + 24, syscall.SIGXCPU, true},
+		{128 + 25, syscall.SIGXFSZ, true},
+		{128 + 2, 0, false}, // not SIGINT, nothing sends it
+		{200, 0, false},
+		{255, 0, false},
+	}
+	for _, tt := range tests {
+		signal, ok := exitSignal(tt.status)
+		if ok != tt.ok || ok && signal != tt.signal {
+			t.Errorf("exitSignal(%d) = %v, %v, want %v, %v", tt.status, signal, ok, tt.signal, tt.ok)
+		}
+	}
+}
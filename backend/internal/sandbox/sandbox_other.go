@@ -0,0 +1,10 @@
+//go:build !linux
+
+package sandbox
+
+import "context"
+
+// Untrusted code is never run without isolation, so other platforms refuse
+func (s *Sandbox) run(ctx context.Context, cmd *Command) (*Result, error) {
+	return nil, ErrUnsupported
+}
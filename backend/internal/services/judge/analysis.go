@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"coderoulette/internal/analytics"
+	"coderoulette/internal/sandbox"
+)
+
+// Formats of analyzer output
+const (
+	AnalyzerLines = "lines" // one finding per "file:line[:column]: message" line
+	AnalyzerDiff  = "diff"  // any output is a single finding, such as a formatter's diff
+)
+
+// maxFindings bounds the findings kept per analyzer
+const maxFindings = 50
+
+// AnalyzerConfig is a trusted linter or formatter run on submitted code
+type AnalyzerConfig struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"` // run in the directory holding the source file
+	Format  string   `json:"format"`  // lines by default, or diff
+	Message string   `json:"message"` // finding message for diff analyzers
+}
+
+// Analysis is feedback on the quality of submitted code. It never affects
+// the verdict or the score.
+type Analysis struct {
+	analytics.Metrics
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is an issue an analyzer reported. Lines and columns refer to the
+// submitted code, 0 when the finding is about the whole program.
+type Finding struct {
+	Tool    string `json:"tool"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"` // such as a formatting diff
+}
+
+// analyze measures submitted code and runs the language's analyzers on the
+// source that was judged. Analyzers that fail to run are skipped, their
+// output only counts when it points into the player's code.
+func (s *JudgeService) analyze(ctx context.Context, code, language string, problem *ProblemData) (*Analysis, error) {
+	analysis := &Analysis{Metrics: analytics.Measure(language, code), Findings: []Finding{}}
+
+	runner, ok := s.languages.Get(language)
+	if !ok || len(runner.Analyzers()) == 0 {
+		return analysis, nil
+	}
+	source, err := prepareSource(problem, language, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Function problems wrap the player's code in a harness, findings
+	// in the harness are dropped and the others shifted to the code
+	offset := strings.Index(source, code)
+	if offset < 0 {
+		return analysis, nil
+	}
+	firstLine := strings.Count(source[:offset], "\n") + 1
+	lastLine := firstLine + strings.Count(code, "\n")
+
+	tempDir, err := os.MkdirTemp("", "analyze_"+runner.Name()+"_*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, runner.SourceFile()), []byte(source), 0644); err != nil {
+		return nil, err
+	}
+
+	for _, analyzer := range runner.Analyzers() {
+		result, err := s.sandbox.Run(ctx, &sandbox.Command{
+			Args:   analyzer.Command,
+			Dir:    tempDir,
+			Env:    runner.Env(),
+			Limits: sandbox.CompileLimits,
+			Mounts: buildCacheMounts(runner),
+		})
+		if err != nil {
+			log.Printf("Analyzer %s for %s: %v", analyzer.Name, runner.Name(), err)
+			continue
+		}
+
+		// A diff on stdout is the finding, errors go to stderr
+		if analyzer.Format == AnalyzerDiff {
+			if strings.TrimSpace(string(result.Stdout)) != "" && len(result.Stderr) == 0 {
+				analysis.Findings = append(analysis.Findings, Finding{
+					Tool:    analyzer.Name,
+					Message: analyzer.Message,
+					Detail:  string(result.Stdout),
+				})
+			}
+			continue
+		}
+
+		output := string(result.Stdout) + string(result.Stderr)
+		findings := parseFindings(analyzer.Name, runner.SourceFile(), output)
+		kept := 0
+		for _, finding := range findings {
+			if finding.Line < firstLine || finding.Line > lastLine || kept == maxFindings {
+				continue
+			}
+			finding.Line -= firstLine - 1
+			analysis.Findings = append(analysis.Findings, finding)
+			kept++
+		}
+	}
+	return analysis, nil
+}
+
+// parseFindings reads "file:line[:column]: message" lines about the source
+// file, ignoring everything else the tool printed
+func parseFindings(tool, sourceFile, output string) []Finding {
+	pattern := regexp.MustCompile(`^(?:\./)?` + regexp.QuoteMeta(sourceFile) + `:(\d+)(?::(\d+))?:\s*(.+)$`)
+
+	var findings []Finding
+	for _, line := range strings.Split(output, "\n") {
+		match := pattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		finding := Finding{Tool: tool, Message: match[3]}
+		finding.Line, _ = strconv.Atoi(match[1])
+		finding.Column, _ = strconv.Atoi(match[2])
+		findings = append(findings, finding)
+	}
+	return findings
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"coderoulette/internal/sandbox"
+)
+
+// CompileCache keeps compiled programs on disk so identical code is built
+// only once. Several judge processes may share the directory: entries are
+// published with an atomic rename and the least recently used ones are
+// evicted when the cache grows past its size limit.
+type CompileCache struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex // serializes eviction within this process
+}
+
+// staleTempAge is how old an unpublished entry must be before eviction
+// assumes its writer died
+const staleTempAge = time.Hour
+
+func NewCompileCache(dir string, maxBytes int64) (*CompileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &CompileCache{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// compileKey identifies a build by language, toolchain version, compile
+// command and source
+func compileKey(runner LanguageRunner, source string) string {
+	hash := sha256.New()
+	parts := []string{runner.Name(), runner.Version(), strings.Join(runner.CompileCommand(), " "), source}
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Restore copies a cached build into dir and reports whether there was one
+func (c *CompileCache) Restore(key, dir string) bool {
+	entry := filepath.Join(c.dir, key)
+	if _, err := os.Stat(entry); err != nil {
+		return false
+	}
+	// Copies, not links: programs can write to their directory and must
+	// not be able to change the cached build
+	if err := copyDir(entry, dir); err != nil {
+		return false
+	}
+
+	now := time.Now()
+	os.Chtimes(entry, now, now)
+	return true
+}
+
+// Store adds the build in dir to the cache and evicts old entries if needed
+func (c *CompileCache) Store(key, dir string) error {
+	entry := filepath.Join(c.dir, key)
+	if _, err := os.Stat(entry); err == nil {
+		return nil
+	}
+
+	temp, err := os.MkdirTemp(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if err := copyDir(dir, temp); err != nil {
+		os.RemoveAll(temp)
+		return err
+	}
+	// Fails if another worker published the same build meanwhile
+	if err := os.Rename(temp, entry); err != nil {
+		os.RemoveAll(temp)
+	}
+
+	c.evict()
+	return nil
+}
+
+// evict removes least recently used entries until the cache fits its limit
+func (c *CompileCache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type cached struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var builds []cached
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			if time.Since(info.ModTime()) > staleTempAge {
+				os.RemoveAll(path)
+			}
+			continue
+		}
+		size := dirSize(path)
+		builds = append(builds, cached{path: path, size: size, modTime: info.ModTime()})
+		total += size
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].modTime.Before(builds[j].modTime)
+	})
+	for _, build := range builds {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.RemoveAll(build.path); err != nil {
+			log.Printf("Compile cache: evict %s: %v", build.path, err)
+			continue
+		}
+		total -= build.size
+	}
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// buildCacheMounts returns the runner's warm build cache as a read-only
+// overlay, or nothing if the cache has not been created yet
+func buildCacheMounts(runner LanguageRunner) []sandbox.Mount {
+	dir, mount := runner.BuildCache()
+	if dir == "" {
+		return nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil
+	}
+	return []sandbox.Mount{{Source: dir, Target: mount}}
+}
+
+// warmLimits bound a warm command, which may build a whole standard library
+var warmLimits = sandbox.Limits{
+	TimeLimit:     10 * time.Minute,
+	WallTimeLimit: 10 * time.Minute,
+	MemoryLimit:   2 << 30,
+	MaxProcesses:  512,
+	MaxOutputSize: 1 << 20,
+}
+
+// WarmBuildCaches runs the warm command of every language with a build
+// cache, with the cache mounted writable. Failures are logged since
+// compiling still works with a cold cache.
+func (s *JudgeService) WarmBuildCaches(ctx context.Context) {
+	for _, runner := range s.languages.Runners() {
+		dir, mount := runner.BuildCache()
+		command := runner.WarmCommand()
+		if dir == "" || len(command) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		if err := s.warmBuildCache(ctx, runner, dir, mount, command); err != nil {
+			log.Printf("Build cache: warming %s failed: %v", runner.Name(), err)
+			continue
+		}
+		log.Printf("Build cache: warmed %s in %s", runner.Name(), time.Since(start).Round(time.Millisecond))
+	}
+}
+
+func (s *JudgeService) warmBuildCache(ctx context.Context, runner LanguageRunner, dir, mount string, command []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	workDir, err := os.MkdirTemp("", "judge_warm_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	result, err := s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   command,
+		Dir:    workDir,
+		Env:    runner.Env(),
+		Limits: warmLimits,
+		Mounts: []sandbox.Mount{{Source: dir, Target: mount, Writable: true}},
+	})
+	if err != nil {
+		return err
+	}
+	if !result.Succeeded() {
+		return fmt.Errorf("exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+	return nil
+}
+
+// copyDir copies the regular files and directories under src into dst,
+// keeping file modes. Anything else, such as symlinks, is skipped.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"coderoulette/internal/sandbox"
+)
+
+// Stress test bounds
+const (
+	defaultStressRuns = 100
+	maxStressRuns     = 1000
+)
+
+// StressOptions controls a stress test
+type StressOptions struct {
+	Generator string `json:"generator"` // empty uses all of them in turn
+	Runs      int    `json:"runs"`      // inputs to try, 100 by default
+	Seed      int64  `json:"seed"`      // seeds the generator seeds, 0 picks one
+}
+
+// StressResult is the outcome of a stress test
+type StressResult struct {
+	Seed           int64           `json:"seed"` // reproduces the run
+	Runs           int             `json:"runs"` // inputs tried
+	Passed         bool            `json:"passed"`
+	Verdict        Verdict         `json:"verdict,omitempty"`   // CE if the candidate does not compile
+	ErrorMsg       string          `json:"error_msg,omitempty"` // compiler output
+	Counterexample *Counterexample `json:"counterexample,omitempty"`
+}
+
+// Counterexample is an input where a candidate disagrees with the reference
+type Counterexample struct {
+	Generator string  `json:"generator"`
+	Seed      string  `json:"seed"`
+	Input     string  `json:"input"`
+	Expected  string  `json:"expected"`
+	Actual    string  `json:"actual"`
+	Verdict   Verdict `json:"verdict"`
+	Message   string  `json:"message,omitempty"`
+}
+
+// GenerateTests replaces the problem's generated tests by running every
+// generator with each of its seeds. Expected outputs come from the
+// reference solution, except on interactive problems where the interactor
+// judges the input alone.
+func (s *JudgeService) GenerateTests(ctx context.Context, problem *ProblemData) error {
+	testCases := []TestCase{}
+	for _, testCase := range problem.TestCases {
+		if testCase.Generated == "" {
+			testCases = append(testCases, testCase)
+		}
+	}
+
+	// Without seeds nothing is generated and the reference is not needed,
+	// it may not even compile yet
+	seeded := false
+	for _, generator := range problem.Generators {
+		seeded = seeded || len(generator.Seeds) > 0
+	}
+	if !seeded {
+		problem.TestCases = testCases
+		return nil
+	}
+
+	var reference *program
+	if !problem.IsInteractive() {
+		prog, err := s.prepareReference(ctx, problem)
+		if err != nil {
+			return err
+		}
+		defer prog.cleanup()
+		reference = prog
+	}
+
+	for _, generator := range problem.Generators {
+		if len(generator.Seeds) == 0 {
+			continue
+		}
+		gen, err := s.prepareGenerator(ctx, generator)
+		if err != nil {
+			return err
+		}
+		defer gen.cleanup()
+
+		for _, seed := range generator.Seeds {
+			input, err := s.runGenerator(ctx, gen, generator.Name, seed)
+			if err != nil {
+				return err
+			}
+			testCase := TestCase{
+				Input:     input,
+				Hidden:    generator.Hidden,
+				Weight:    generator.Weight,
+				Group:     generator.Group,
+				Generated: generatedTest(generator.Name, seed),
+			}
+			if reference != nil {
+				if testCase.Output, err = s.runReference(ctx, reference, problem, input); err != nil {
+					return fmt.Errorf("generator %s seed %s: %w", generator.Name, seed, err)
+				}
+			}
+			testCases = append(testCases, testCase)
+		}
+	}
+
+	problem.TestCases = testCases
+	return nil
+}
+
+// StressTest runs a candidate solution and the reference solution on
+// random generated inputs and stops at the first input where the
+// candidate's output does not pass the problem's checker
+func (s *JudgeService) StressTest(ctx context.Context, problem *ProblemData, code, language string, opts StressOptions) (*StressResult, error) {
+	if problem.IsInteractive() {
+		return nil, fmt.Errorf("%w: interactive problems cannot be stress tested", ErrInvalidInput)
+	}
+	if problem.Solution == "" {
+		return nil, fmt.Errorf("%w: problem has no reference solution", ErrInvalidInput)
+	}
+
+	var generators []Generator
+	for _, generator := range problem.Generators {
+		if opts.Generator == "" || generator.Name == opts.Generator {
+			generators = append(generators, generator)
+		}
+	}
+	if len(generators) == 0 {
+		return nil, fmt.Errorf("%w: no generator to stress test with", ErrInvalidInput)
+	}
+
+	if opts.Runs <= 0 {
+		opts.Runs = defaultStressRuns
+	}
+	opts.Runs = min(opts.Runs, maxStressRuns)
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	// Compile the candidate first, its compile errors are the player's
+	source, err := prepareSource(problem, language, code)
+	if err != nil {
+		return nil, err
+	}
+	candidate, failure, err := s.prepareProgram(ctx, source, language)
+	if err != nil {
+		return nil, err
+	}
+	result := &StressResult{Seed: opts.Seed}
+	if failure != nil {
+		result.Verdict = failure.Verdict
+		result.ErrorMsg = failure.ErrorMsg
+		return result, nil
+	}
+	defer candidate.cleanup()
+
+	reference, err := s.prepareReference(ctx, problem)
+	if err != nil {
+		return nil, err
+	}
+	defer reference.cleanup()
+
+	gens := make([]*program, len(generators))
+	for i, generator := range generators {
+		if gens[i], err = s.prepareGenerator(ctx, generator); err != nil {
+			return nil, err
+		}
+		defer gens[i].cleanup()
+	}
+
+	judge, err := s.newTestJudge(ctx, candidate, problem, problem.Limits.Sandbox(candidate.runner.DefaultLimits()))
+	if err != nil {
+		return nil, err
+	}
+	defer judge.close()
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	for result.Runs < opts.Runs {
+		generator := generators[result.Runs%len(generators)]
+		seed := strconv.FormatInt(rng.Int63(), 10)
+
+		input, err := s.runGenerator(ctx, gens[result.Runs%len(generators)], generator.Name, seed)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := s.runReference(ctx, reference, problem, input)
+		if err != nil {
+			return nil, fmt.Errorf("generator %s seed %s: %w", generator.Name, seed, err)
+		}
+
+		test, _, err := judge.runTest(ctx, result.Runs, TestCase{Input: input, Output: expected})
+		if err != nil {
+			return nil, err
+		}
+		result.Runs++
+
+		if !test.Passed {
+			result.Counterexample = &Counterexample{
+				Generator: generator.Name,
+				Seed:      seed,
+				Input:     input,
+				Expected:  test.Expected,
+				Actual:    test.Actual,
+				Verdict:   test.Verdict,
+				Message:   test.Message,
+			}
+			return result, nil
+		}
+	}
+
+	result.Passed = true
+	return result, nil
+}
+
+// prepareReference compiles the problem's reference solution
+func (s *JudgeService) prepareReference(ctx context.Context, problem *ProblemData) (*program, error) {
+	source, err := prepareSource(problem, problem.Language, problem.Solution)
+	if err != nil {
+		return nil, fmt.Errorf("reference solution: %w", err)
+	}
+	prog, failure, err := s.prepareProgram(ctx, source, problem.Language)
+	if err != nil {
+		return nil, fmt.Errorf("reference solution: %w", err)
+	}
+	if failure != nil {
+		return nil, fmt.Errorf("reference solution does not compile: %s", failure.ErrorMsg)
+	}
+	return prog, nil
+}
+
+// runReference returns the reference solution's output for an input, which
+// it has to produce cleanly within the problem's limits
+func (s *JudgeService) runReference(ctx context.Context, prog *program, problem *ProblemData, input string) (string, error) {
+	result, err := s.runOnInput(ctx, prog, problem, problem.Limits.Sandbox(prog.runner.DefaultLimits()), input)
+	if err != nil {
+		return "", err
+	}
+	if verdict := runVerdict(result); verdict != VerdictAccepted {
+		return "", fmt.Errorf("reference solution failed with %s: %s", verdict, strings.TrimSpace(string(result.Stderr)))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// prepareGenerator compiles a test generator
+func (s *JudgeService) prepareGenerator(ctx context.Context, generator Generator) (*program, error) {
+	prog, failure, err := s.prepareProgram(ctx, generator.Code, generator.Language)
+	if err != nil {
+		return nil, fmt.Errorf("generator %s: %w", generator.Name, err)
+	}
+	if failure != nil {
+		return nil, fmt.Errorf("generator %s does not compile: %s", generator.Name, failure.ErrorMsg)
+	}
+	return prog, nil
+}
+
+// runGenerator returns the input a generator prints for a seed
+func (s *JudgeService) runGenerator(ctx context.Context, prog *program, name, seed string) (string, error) {
+	result, err := s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   append(append([]string{}, prog.runner.RunCommand()...), seed),
+		Dir:    prog.dir,
+		Env:    prog.runner.Env(),
+		Limits: prog.runner.DefaultLimits(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("generator %s: %w", name, err)
+	}
+	if !result.Succeeded() {
+		return "", fmt.Errorf("generator %s seed %s failed with %s: %s", name, seed, runVerdict(result), strings.TrimSpace(string(result.Stderr)))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
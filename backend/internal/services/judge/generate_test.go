@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGenerateTestsWithoutSeeds(t *testing.T) {
+	problem := &ProblemData{
+		Language: "cpp",
+		Solution: "", // would not compile
+		TestCases: []TestCase{
+			{Input: "1", Output: "1"},
+			{Input: "2", Output: "2", Generated: "gen#old"},
+		},
+		Generators: []Generator{{Name: "gen", Language: "python"}},
+	}
+
+	// The judge has no sandbox, anything that runs code fails
+	judge := &JudgeService{}
+	if err := judge.GenerateTests(context.Background(), problem); err != nil {
+		t.Fatalf("GenerateTests: %v", err)
+	}
+	if len(problem.TestCases) != 1 || problem.TestCases[0].Input != "1" {
+		t.Errorf("test cases = %+v, want only the hand-written one", problem.TestCases)
+	}
+}
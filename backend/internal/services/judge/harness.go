@@ -0,0 +1,578 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// harness generates the driver that turns a player's function into a
+// complete program for one language. The driver reads the arguments in
+// the token format produced by Signature.EncodeArgs, calls the function
+// and prints its return value as JSON.
+type harness struct {
+	wrap    func(sig *Signature, code string) string
+	starter func(sig *Signature) string
+}
+
+// harnesses are keyed by the registry name of the language they target
+var harnesses = map[string]harness{
+	"go":         {wrap: wrapGo, starter: starterGo},
+	"python":     {wrap: wrapPython, starter: starterPython},
+	"javascript": {wrap: wrapJavaScript, starter: starterJavaScript},
+	"typescript": {wrap: wrapTypeScript, starter: starterTypeScript},
+	"java":       {wrap: wrapJava, starter: starterJava},
+	"cpp":        {wrap: wrapCpp, starter: starterCpp},
+}
+
+// HasHarness reports whether function problems can be solved in a language
+func HasHarness(language string) bool {
+	_, ok := harnesses[language]
+	return ok
+}
+
+// WrapFunction returns the complete program for a player's implementation
+// of sig in the given language
+func WrapFunction(language string, sig *Signature, code string) (string, error) {
+	h, ok := harnesses[language]
+	if !ok {
+		return "", fmt.Errorf("%w: function problems are not available in %s", ErrUnsupportedLanguage, language)
+	}
+	if err := sig.Validate(); err != nil {
+		return "", err
+	}
+	return h.wrap(sig, code), nil
+}
+
+// StarterCode returns an empty implementation of sig for every language
+// that has a harness
+func StarterCode(sig *Signature) map[string]string {
+	if sig.Validate() != nil {
+		return nil
+	}
+	starters := make(map[string]string, len(harnesses))
+	for language, h := range harnesses {
+		starters[language] = h.starter(sig)
+	}
+	return starters
+}
+
+// HarnessLanguages lists the languages function problems can be solved in
+func HarnessLanguages() []string {
+	languages := make([]string, 0, len(harnesses))
+	for language := range harnesses {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// prepareSource returns the source to compile for a submission: the code
+// as is for stdin/stdout problems, wrapped in a driver for function problems
+func prepareSource(problem *ProblemData, language, code string) (string, error) {
+	if problem == nil || problem.Signature == nil {
+		return code, nil
+	}
+	return WrapFunction(language, problem.Signature, code)
+}
+
+// testInput returns what the program reads on stdin for a test input
+func testInput(problem *ProblemData, input string) (string, error) {
+	if problem == nil || problem.Signature == nil {
+		return input, nil
+	}
+	return problem.Signature.EncodeArgs(input)
+}
+
+// canonicalJSON re-encodes a JSON value, invalid JSON is returned unchanged
+func canonicalJSON(s string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return s
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return s
+	}
+	return string(canonical)
+}
+
+// signatureTypes returns the parsed parameter types of a validated signature
+func signatureTypes(sig *Signature) []ValueType {
+	types := make([]ValueType, len(sig.Params))
+	for i, param := range sig.Params {
+		types[i], _ = ParseValueType(param.Type)
+	}
+	return types
+}
+
+func returnType(sig *Signature) ValueType {
+	t, _ := ParseValueType(sig.Returns)
+	return t
+}
+
+func paramNames(sig *Signature) []string {
+	names := make([]string, len(sig.Params))
+	for i, param := range sig.Params {
+		names[i] = param.Name
+	}
+	return names
+}
+
+// Go
+
+var goTypes = map[string]string{"int": "int", "long": "int64", "double": "float64", "bool": "bool", "string": "string"}
+var goReaders = map[string]string{"int": "_r.Int()", "long": "_r.Int64()", "double": "_r.Float()", "bool": "_r.Bool()", "string": "_r.Str()"}
+
+func goType(t ValueType) string {
+	return strings.Repeat("[]", t.Dims) + goTypes[t.Base]
+}
+
+func goRead(t ValueType) string {
+	if t.Dims == 0 {
+		return goReaders[t.Base]
+	}
+	return fmt.Sprintf("_readSlice(_r, func() %s { return %s })", goType(t.Elem()), goRead(t.Elem()))
+}
+
+// goImports go right after the package clause, on the same line so
+// compiler errors keep pointing at the player's line numbers
+const goImports = `; import (_bufio "bufio"; _json "encoding/json"; _os "os"; _strconv "strconv")`
+
+const goDriver = `
+type _reader struct{ r *_bufio.Reader }
+
+func (r *_reader) token() string {
+	var buf []byte
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			break
+		}
+		if c <= ' ' {
+			if len(buf) > 0 {
+				break
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+func (r *_reader) Int() int       { n, _ := _strconv.Atoi(r.token()); return n }
+func (r *_reader) Int64() int64   { n, _ := _strconv.ParseInt(r.token(), 10, 64); return n }
+func (r *_reader) Float() float64 { f, _ := _strconv.ParseFloat(r.token(), 64); return f }
+func (r *_reader) Bool() bool     { return r.token() == "true" }
+
+func (r *_reader) Str() string {
+	buf := make([]byte, r.Int())
+	for i := range buf {
+		buf[i], _ = r.r.ReadByte()
+	}
+	return string(buf)
+}
+
+func _readSlice[T any](r *_reader, read func() T) []T {
+	s := make([]T, r.Int())
+	for i := range s {
+		s[i] = read()
+	}
+	return s
+}
+`
+
+func wrapGo(sig *Signature, code string) string {
+	// Players may leave out the package clause
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, parser.PackageClauseOnly)
+	if err != nil {
+		code = "package main; " + code
+		file, err = parser.ParseFile(fset, "main.go", code, parser.PackageClauseOnly)
+	}
+	if err == nil {
+		offset := fset.Position(file.Name.End()).Offset
+		code = code[:offset] + goImports + code[offset:]
+	}
+
+	var b strings.Builder
+	b.WriteString(code)
+	b.WriteString("\n")
+	b.WriteString(goDriver)
+	b.WriteString("\nfunc main() {\n\t_r := &_reader{r: _bufio.NewReader(_os.Stdin)}\n")
+	for i, t := range signatureTypes(sig) {
+		fmt.Fprintf(&b, "\t%s := %s\n", sig.Params[i].Name, goRead(t))
+	}
+	fmt.Fprintf(&b, "\t_out, _ := _json.Marshal(%s(%s))\n", sig.Function, strings.Join(paramNames(sig), ", "))
+	b.WriteString("\t_os.Stdout.Write(_out)\n}\n")
+	return b.String()
+}
+
+func starterGo(sig *Signature) string {
+	params := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		params[i] = sig.Params[i].Name + " " + goType(t)
+	}
+	return fmt.Sprintf("package main\n\nfunc %s(%s) %s {\n\t\n}\n", sig.Function, strings.Join(params, ", "), goType(returnType(sig)))
+}
+
+// Python
+
+var pythonTypes = map[string]string{"int": "int", "long": "int", "double": "float", "bool": "bool", "string": "str"}
+var pythonReaders = map[string]string{"int": "_r.int()", "long": "_r.int()", "double": "_r.float()", "bool": "_r.bool()", "string": "_r.str()"}
+
+func pythonType(t ValueType) string {
+	if t.Dims == 0 {
+		return pythonTypes[t.Base]
+	}
+	return "list[" + pythonType(t.Elem()) + "]"
+}
+
+func pythonRead(t ValueType) string {
+	if t.Dims == 0 {
+		return pythonReaders[t.Base]
+	}
+	return "_r.list(lambda: " + pythonRead(t.Elem()) + ")"
+}
+
+const pythonDriver = `
+
+import sys as _sys, json as _json
+
+
+class _Reader:
+    def __init__(self, data):
+        self.data = data
+        self.pos = 0
+
+    def token(self):
+        data, n = self.data, len(self.data)
+        while self.pos < n and data[self.pos] <= 32:
+            self.pos += 1
+        start = self.pos
+        while self.pos < n and data[self.pos] > 32:
+            self.pos += 1
+        token = data[start:self.pos]
+        self.pos += 1
+        return token
+
+    def int(self):
+        return int(self.token())
+
+    def float(self):
+        return float(self.token())
+
+    def bool(self):
+        return self.token() == b"true"
+
+    def str(self):
+        n = self.int()
+        s = self.data[self.pos:self.pos + n].decode("utf-8")
+        self.pos += n
+        return s
+
+    def list(self, read):
+        return [read() for _ in range(self.int())]
+
+
+_r = _Reader(_sys.stdin.buffer.read())
+`
+
+func wrapPython(sig *Signature, code string) string {
+	args := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		args[i] = pythonRead(t)
+	}
+	// Python evaluates arguments left to right, matching the input order
+	return fmt.Sprintf("%s\n%s_sys.stdout.write(_json.dumps(%s(%s), separators=(\",\", \":\")))\n",
+		code, pythonDriver, sig.Function, strings.Join(args, ", "))
+}
+
+func starterPython(sig *Signature) string {
+	params := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		params[i] = sig.Params[i].Name + ": " + pythonType(t)
+	}
+	return fmt.Sprintf("def %s(%s) -> %s:\n    pass\n", sig.Function, strings.Join(params, ", "), pythonType(returnType(sig)))
+}
+
+// JavaScript and TypeScript
+
+var scriptTypes = map[string]string{"int": "number", "long": "number", "double": "number", "bool": "boolean", "string": "string"}
+var scriptReaders = map[string]string{"int": "_num()", "long": "_num()", "double": "_num()", "bool": "_bool()", "string": "_str()"}
+
+func scriptType(t ValueType) string {
+	return scriptTypes[t.Base] + strings.Repeat("[]", t.Dims)
+}
+
+func scriptRead(t ValueType) string {
+	if t.Dims == 0 {
+		return scriptReaders[t.Base]
+	}
+	return "_list(() => " + scriptRead(t.Elem()) + ")"
+}
+
+const javaScriptDriver = `
+const _data = require('fs').readFileSync(0);
+let _pos = 0;
+function _token() {
+  while (_pos < _data.length && _data[_pos] <= 32) _pos++;
+  const start = _pos;
+  while (_pos < _data.length && _data[_pos] > 32) _pos++;
+  const token = _data.toString('utf8', start, _pos);
+  _pos++;
+  return token;
+}
+function _num() { return Number(_token()); }
+function _bool() { return _token() === 'true'; }
+function _str() {
+  const n = _num();
+  const s = _data.toString('utf8', _pos, _pos + n);
+  _pos += n;
+  return s;
+}
+function _list(read) {
+  const n = _num();
+  const list = [];
+  for (let i = 0; i < n; i++) list.push(read());
+  return list;
+}
+`
+
+const typeScriptDriver = `
+const _data: Buffer = require('fs').readFileSync(0);
+let _pos = 0;
+function _token(): string {
+  while (_pos < _data.length && _data[_pos] <= 32) _pos++;
+  const start = _pos;
+  while (_pos < _data.length && _data[_pos] > 32) _pos++;
+  const token = _data.toString('utf8', start, _pos);
+  _pos++;
+  return token;
+}
+function _num(): number { return Number(_token()); }
+function _bool(): boolean { return _token() === 'true'; }
+function _str(): string {
+  const n = _num();
+  const s = _data.toString('utf8', _pos, _pos + n);
+  _pos += n;
+  return s;
+}
+function _list<T>(read: () => T): T[] {
+  const n = _num();
+  const list: T[] = [];
+  for (let i = 0; i < n; i++) list.push(read());
+  return list;
+}
+`
+
+func wrapScript(sig *Signature, code, driver string) string {
+	args := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		args[i] = scriptRead(t)
+	}
+	return fmt.Sprintf("%s\n%sprocess.stdout.write(String(JSON.stringify(%s(%s))));\n",
+		code, driver, sig.Function, strings.Join(args, ", "))
+}
+
+func wrapJavaScript(sig *Signature, code string) string {
+	return wrapScript(sig, code, javaScriptDriver)
+}
+
+func wrapTypeScript(sig *Signature, code string) string {
+	return wrapScript(sig, code, typeScriptDriver)
+}
+
+func starterJavaScript(sig *Signature) string {
+	return fmt.Sprintf("function %s(%s) {\n  \n}\n", sig.Function, strings.Join(paramNames(sig), ", "))
+}
+
+func starterTypeScript(sig *Signature) string {
+	params := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		params[i] = sig.Params[i].Name + ": " + scriptType(t)
+	}
+	return fmt.Sprintf("function %s(%s): %s {\n  \n}\n", sig.Function, strings.Join(params, ", "), scriptType(returnType(sig)))
+}
+
+// Java
+
+var javaTypes = map[string]string{"int": "int", "long": "long", "double": "double", "bool": "boolean", "string": "String"}
+var javaReaders = map[string]string{"int": "readInt()", "long": "readLong()", "double": "readDouble()", "bool": "readBool()", "string": "readString()"}
+
+func javaType(t ValueType) string {
+	return javaTypes[t.Base] + strings.Repeat("[]", t.Dims)
+}
+
+// javaRead returns statements that read a value of type t into target
+func javaRead(target string, t ValueType, depth int) string {
+	if t.Dims == 0 {
+		return fmt.Sprintf("%s = %s;", target, javaReaders[t.Base])
+	}
+	i := fmt.Sprintf("_i%d", depth)
+	return fmt.Sprintf("%s = new %s[readInt()]%s; for (int %s = 0; %s < %s.length; %s++) { %s }",
+		target, javaTypes[t.Base], strings.Repeat("[]", t.Dims-1), i, i, target, i,
+		javaRead(target+"["+i+"]", t.Elem(), depth+1))
+}
+
+const javaDriver = `
+public class Main {
+    static java.io.DataInputStream in = new java.io.DataInputStream(new java.io.BufferedInputStream(System.in, 1 << 16));
+
+    static String token() throws java.io.IOException {
+        StringBuilder sb = new StringBuilder();
+        int c = in.read();
+        while (c != -1 && c <= ' ') c = in.read();
+        while (c != -1 && c > ' ') {
+            sb.append((char) c);
+            c = in.read();
+        }
+        return sb.toString();
+    }
+
+    static int readInt() throws java.io.IOException { return Integer.parseInt(token()); }
+    static long readLong() throws java.io.IOException { return Long.parseLong(token()); }
+    static double readDouble() throws java.io.IOException { return Double.parseDouble(token()); }
+    static boolean readBool() throws java.io.IOException { return token().equals("true"); }
+
+    static String readString() throws java.io.IOException {
+        byte[] bytes = new byte[readInt()];
+        in.readFully(bytes);
+        return new String(bytes, java.nio.charset.StandardCharsets.UTF_8);
+    }
+
+    static void json(StringBuilder sb, Object o) {
+        if (o == null) {
+            sb.append("null");
+        } else if (o instanceof String) {
+            String s = (String) o;
+            sb.append('"');
+            for (int i = 0; i < s.length(); i++) {
+                char c = s.charAt(i);
+                if (c == '"' || c == '\\') sb.append('\\').append(c);
+                else if (c < 0x20) sb.append(String.format("\\u%04x", (int) c));
+                else sb.append(c);
+            }
+            sb.append('"');
+        } else if (o.getClass().isArray()) {
+            sb.append('[');
+            for (int i = 0; i < java.lang.reflect.Array.getLength(o); i++) {
+                if (i > 0) sb.append(',');
+                json(sb, java.lang.reflect.Array.get(o, i));
+            }
+            sb.append(']');
+        } else {
+            sb.append(o);
+        }
+    }
+
+    public static void main(String[] _args) throws java.io.IOException {
+`
+
+func wrapJava(sig *Signature, code string) string {
+	var b strings.Builder
+	b.WriteString(code)
+	b.WriteString("\n")
+	b.WriteString(javaDriver)
+	for i, t := range signatureTypes(sig) {
+		name := sig.Params[i].Name
+		fmt.Fprintf(&b, "        %s %s; %s\n", javaType(t), name, javaRead(name, t, 0))
+	}
+	b.WriteString("        StringBuilder _sb = new StringBuilder();\n")
+	fmt.Fprintf(&b, "        json(_sb, Solution.%s(%s));\n", sig.Function, strings.Join(paramNames(sig), ", "))
+	b.WriteString("        System.out.print(_sb);\n    }\n}\n")
+	return b.String()
+}
+
+func starterJava(sig *Signature) string {
+	params := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		params[i] = javaType(t) + " " + sig.Params[i].Name
+	}
+	return fmt.Sprintf("class Solution {\n    public static %s %s(%s) {\n        \n    }\n}\n",
+		javaType(returnType(sig)), sig.Function, strings.Join(params, ", "))
+}
+
+// C++
+
+var cppTypes = map[string]string{"int": "int", "long": "long long", "double": "double", "bool": "bool", "string": "string"}
+
+// cppType spells t for code after "using namespace std" or, with std set,
+// for the driver which can't rely on it
+func cppType(t ValueType, std string) string {
+	if t.Dims == 0 {
+		if t.Base == "string" {
+			return std + "string"
+		}
+		return cppTypes[t.Base]
+	}
+	return std + "vector<" + cppType(t.Elem(), std) + ">"
+}
+
+const cppDriver = `
+#include <cstdio>
+#include <iostream>
+#include <string>
+#include <vector>
+
+namespace _harness {
+using namespace std;
+
+void rd(int& x) { cin >> x; }
+void rd(long long& x) { cin >> x; }
+void rd(double& x) { cin >> x; }
+void rd(bool& x) { string t; cin >> t; x = t == "true"; }
+void rd(string& x) { size_t n; cin >> n; cin.get(); x.resize(n); cin.read(&x[0], n); }
+void rd(vector<bool>& v) { size_t n; cin >> n; v.resize(n); for (size_t i = 0; i < n; i++) { bool b; rd(b); v[i] = b; } }
+template <class T> void rd(vector<T>& v) { size_t n; cin >> n; v.resize(n); for (auto& x : v) rd(x); }
+
+void wr(int x) { cout << x; }
+void wr(long long x) { cout << x; }
+void wr(double x) { char buf[32]; snprintf(buf, sizeof buf, "%.17g", x); cout << buf; }
+void wr(bool x) { cout << (x ? "true" : "false"); }
+void wr(const string& s) {
+    cout << '"';
+    for (unsigned char c : s) {
+        if (c == '"' || c == '\\') cout << '\\' << c;
+        else if (c < 0x20) { char buf[8]; snprintf(buf, sizeof buf, "\\u%04x", c); cout << buf; }
+        else cout << c;
+    }
+    cout << '"';
+}
+template <class T> void wr(const vector<T>& v) {
+    cout << '[';
+    for (size_t i = 0; i < v.size(); i++) { if (i) cout << ','; wr((T)v[i]); }
+    cout << ']';
+}
+}
+
+int main() {
+    std::ios::sync_with_stdio(false);
+`
+
+func wrapCpp(sig *Signature, code string) string {
+	var b strings.Builder
+	b.WriteString(code)
+	b.WriteString("\n")
+	b.WriteString(cppDriver)
+	for i, t := range signatureTypes(sig) {
+		// Read into locals first, argument evaluation order is unspecified
+		name := sig.Params[i].Name
+		fmt.Fprintf(&b, "    %s %s; _harness::rd(%s);\n", cppType(t, "std::"), name, name)
+	}
+	fmt.Fprintf(&b, "    _harness::wr(%s(%s));\n", sig.Function, strings.Join(paramNames(sig), ", "))
+	b.WriteString("    return 0;\n}\n")
+	return b.String()
+}
+
+func starterCpp(sig *Signature) string {
+	params := make([]string, len(sig.Params))
+	for i, t := range signatureTypes(sig) {
+		params[i] = cppType(t, "") + " " + sig.Params[i].Name
+	}
+	return fmt.Sprintf("#include <string>\n#include <vector>\nusing namespace std;\n\n%s %s(%s) {\n    \n}\n",
+		cppType(returnType(sig), ""), sig.Function, strings.Join(params, ", "))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"coderoulette/internal/sandbox"
+)
+
+// runInteractive runs the player's program with its stdin and stdout
+// cross-connected to the interactor, each in its own sandbox and limits
+func (j *testJudge) runInteractive(ctx context.Context, index int, testCase TestCase) (*sandbox.Result, *checkResult, error) {
+	// Every test gets its own input file so tests may run side by side
+	inputFile := fmt.Sprintf("input_%d.txt", index+1)
+	if err := os.WriteFile(filepath.Join(j.interactor.dir, inputFile), []byte(testCase.Input), 0644); err != nil {
+		return nil, nil, err
+	}
+
+	toInteractorR, toInteractorW, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	toPlayerR, toPlayerW, err := os.Pipe()
+	if err != nil {
+		toInteractorR.Close()
+		toInteractorW.Close()
+		return nil, nil, err
+	}
+
+	// The interactor outlives the player so it can still judge a player
+	// that was killed
+	interactorLimits := j.interactor.runner.DefaultLimits()
+	if minWall := j.limits.WallTimeLimit + time.Second; interactorLimits.WallTimeLimit < minWall {
+		interactorLimits.WallTimeLimit = minWall
+	}
+
+	var interactorResult *sandbox.Result
+	var interactorErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		interactorResult, interactorErr = j.s.sandbox.Run(ctx, &sandbox.Command{
+			Args:   append(append([]string{}, j.interactor.runner.RunCommand()...), inputFile),
+			Dir:    j.interactor.dir,
+			Env:    j.interactor.runner.Env(),
+			Stdin:  toInteractorR,
+			Stdout: toPlayerW,
+			Limits: interactorLimits,
+		})
+		// Our copies of the pipe ends would keep the player from seeing
+		// EOF or block it on a full pipe
+		toPlayerW.Close()
+		toInteractorR.Close()
+	}()
+
+	playerResult, err := j.s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   j.prog.runner.RunCommand(),
+		Dir:    j.prog.dir,
+		Env:    j.prog.runner.Env(),
+		Stdin:  toPlayerR,
+		Stdout: toInteractorW,
+		Limits: j.limits,
+	})
+	toInteractorW.Close()
+	toPlayerR.Close()
+	<-done
+
+	if err != nil {
+		return nil, nil, err
+	}
+	if interactorErr != nil {
+		return nil, nil, fmt.Errorf("interactor: %w", interactorErr)
+	}
+
+	check, err := interactionVerdict(playerResult, interactorResult)
+	if err != nil {
+		return nil, nil, err
+	}
+	return playerResult, check, nil
+}
+
+// interactionVerdict combines how the player's program and the interactor
+// finished. Limit violations of the player come first; a wrong answer from
+// the interactor beats a crash of the player, which may just be the player
+// writing to an interactor that already hung up.
+func interactionVerdict(player, interactor *sandbox.Result) (*checkResult, error) {
+	verdict := runVerdict(player)
+	switch verdict {
+	case VerdictTimeLimitExceeded, VerdictMemoryLimitExceeded, VerdictOutputLimitExceeded:
+		return &checkResult{Verdict: verdict}, nil
+	}
+
+	interactorExited := interactor.Signal == 0 && !interactor.TimeLimitExceeded &&
+		!interactor.MemoryLimitExceeded && !interactor.OutputLimitExceeded
+	message := strings.TrimSpace(string(interactor.Stderr))
+
+	switch {
+	case interactorExited && interactor.ExitCode == interactorWrongAnswer:
+		return &checkResult{Verdict: VerdictWrongAnswer, Message: message}, nil
+	case verdict != VerdictAccepted:
+		return &checkResult{Verdict: verdict}, nil
+	case interactorExited && interactor.ExitCode == interactorAccepted:
+		return &checkResult{Verdict: VerdictAccepted, Score: 100, Message: message}, nil
+	default:
+		return nil, fmt.Errorf("interactor failed with exit code %d, signal %d: %s", interactor.ExitCode, interactor.Signal, message)
+	}
+}
@@ -2,62 +2,208 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"coderoulette/internal/database"
+	"coderoulette/internal/mq"
+	"coderoulette/internal/sandbox"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrMatchNotFound = errors.New("match not found")
+	ErrNotInMatch    = errors.New("player is not part of this match")
+	ErrNoProblem     = errors.New("match has no problem assigned")
+
+	ErrSubmissionNotFound = errors.New("submission not found")
+
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	ErrInvalidInput        = errors.New("invalid input")
+
+	ErrJudgeUnavailable = errors.New("judge is unavailable") // remote judge cannot be reached
+)
+
 type JudgeService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	sandbox   *sandbox.Sandbox
+	queue     *mq.Queue
+	languages *LanguageRegistry
+	cache     *CompileCache
+
+	testParallelism int // tests of one submission run at once
+
+	// Judged submissions are compared with earlier ones when set
+	plagiarism *PlagiarismService
+
+	analysis bool // attach code quality feedback to judged submissions
+
+	ratings RatingSystem // rates matches again when a rejudge changes the winner
 }
 
 type JudgeResult struct {
-	Status    string           `json:"status"`    // passed, failed, error
+	Verdict   Verdict          `json:"verdict"`   // verdict of the first failing test, AC if all pass
 	Score     int              `json:"score"`     // 0-100
-	Runtime   int              `json:"runtime"`   // in milliseconds
+	Runtime   int              `json:"runtime"`   // CPU time of all tests in milliseconds
+	Memory    int              `json:"memory"`    // peak memory of any test in kilobytes
 	ErrorMsg  string           `json:"error_msg"` // error message if any
 	TestCases []TestCaseResult `json:"test_cases"`
+	Subtasks  []SubtaskResult  `json:"subtasks,omitempty"` // set when the problem has subtasks
+	Analysis  *Analysis        `json:"analysis,omitempty"` // code quality feedback, set when analysis is enabled
 }
 
 type TestCaseResult struct {
-	Input    string `json:"input"`
-	Expected string `json:"expected"`
-	Actual   string `json:"actual"`
-	Passed   bool   `json:"passed"`
-	Runtime  int    `json:"runtime"`
+	Input    string  `json:"input"`
+	Expected string  `json:"expected"`
+	Actual   string  `json:"actual"`
+	Passed   bool    `json:"passed"`
+	Runtime  int     `json:"runtime"` // CPU time in milliseconds
+	Memory   int     `json:"memory"`  // peak memory in kilobytes
+	Verdict  Verdict `json:"verdict"`
+	ExitCode int     `json:"exit_code"`
+	Signal   string  `json:"signal,omitempty"`
+	Hidden   bool    `json:"hidden"`
+	Group    string  `json:"group,omitempty"`
+	Score    int     `json:"score"`             // percentage of the test's weight awarded
+	Message  string  `json:"message,omitempty"` // from a custom checker
 }
 
 type SubmissionData struct {
-	ID        uuid.UUID `json:"id"`
-	MatchID   uuid.UUID `json:"match_id"`
-	PlayerID  uuid.UUID `json:"player_id"`
-	Code      string    `json:"code"`
-	Language  string    `json:"language"`
-	Status    string    `json:"status"`
-	Score     int       `json:"score"`
-	Runtime   int       `json:"runtime"`
-	ErrorMsg  string    `json:"error_msg"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uuid.UUID        `json:"id"`
+	MatchID     uuid.UUID        `json:"match_id"`
+	PlayerID    uuid.UUID        `json:"player_id"`
+	Code        string           `json:"code"`
+	Language    string           `json:"language"`
+	Mode        string           `json:"mode"`
+	Status      string           `json:"status"`
+	Verdict     Verdict          `json:"verdict"`
+	Score       int              `json:"score"`
+	Runtime     int              `json:"runtime"`
+	Memory      int              `json:"memory"`
+	ErrorMsg    string           `json:"error_msg"`
+	TestResults []TestCaseResult `json:"test_results"`
+	Subtasks    []SubtaskResult  `json:"subtasks,omitempty"`
+	Progress    Progress         `json:"progress"`
+	Analysis    *Analysis        `json:"analysis,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// RunResult is the outcome of running code on custom input
+type RunResult struct {
+	Verdict  Verdict `json:"verdict"`
+	Stdout   string  `json:"stdout"`
+	Stderr   string  `json:"stderr"`
+	ExitCode int     `json:"exit_code"`
+	Signal   string  `json:"signal,omitempty"`
+	Runtime  int     `json:"runtime"`   // CPU time in milliseconds
+	WallTime int     `json:"wall_time"` // in milliseconds
+	Memory   int     `json:"memory"`    // peak memory in kilobytes
+}
+
+// Progress reports how many test cases of a submission have been judged
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 func NewJudgeService() *JudgeService {
-	return &JudgeService{}
+	return &JudgeService{
+		sandbox:         sandbox.New(sandbox.DefaultCgroupRoot),
+		languages:       DefaultLanguageRegistry(),
+		testParallelism: 1,
+		ratings:         Glicko2{Tau: 0.5},
+	}
 }
 
 func (s *JudgeService) SetDB(db *gorm.DB) {
 	s.db = db
 }
 
-// SubmitCode submits code for judging
-func (s *JudgeService) SubmitCode(ctx context.Context, matchID, playerID uuid.UUID, code, language string, testCases []TestCase) (*JudgeResult, error) {
+func (s *JudgeService) SetSandbox(sb *sandbox.Sandbox) {
+	s.sandbox = sb
+}
+
+func (s *JudgeService) SetQueue(queue *mq.Queue) {
+	s.queue = queue
+}
+
+func (s *JudgeService) SetLanguages(languages *LanguageRegistry) {
+	s.languages = languages
+}
+
+func (s *JudgeService) SetCompileCache(cache *CompileCache) {
+	s.cache = cache
+}
+
+// SetTestParallelism sets how many tests of one submission run at once
+func (s *JudgeService) SetTestParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.testParallelism = n
+}
+
+func (s *JudgeService) SetRatingSystem(ratings RatingSystem) {
+	s.ratings = ratings
+}
+
+// SetAnalysis turns the code quality analysis of judged submissions on or off
+func (s *JudgeService) SetAnalysis(enabled bool) {
+	s.analysis = enabled
+}
+
+// SetPlagiarism makes workers scan each judged submission for similar code
+func (s *JudgeService) SetPlagiarism(plagiarism *PlagiarismService) {
+	s.plagiarism = plagiarism
+}
+
+// Languages returns the registry of languages the judge accepts
+func (s *JudgeService) Languages() *LanguageRegistry {
+	return s.languages
+}
+
+// SubmitCode records a submission and queues it for judging against the
+// tests of the match's problem in the given mode, ModeRunAll if empty. The
+// returned submission is pending; poll GetSubmission for progress and the
+// verdict.
+func (s *JudgeService) SubmitCode(ctx context.Context, matchID, playerID uuid.UUID, code, language, mode string) (*SubmissionData, error) {
+	if !s.languages.Has(language) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
+	}
+	if mode == "" {
+		mode = ModeRunAll
+	}
+	if !ValidMode(mode) {
+		return nil, fmt.Errorf("%w: unknown judging mode %q", ErrInvalidInput, mode)
+	}
+
+	var match database.Match
+	if err := s.db.First(&match, "id = ?", matchID).Error; err != nil {
+		return nil, ErrMatchNotFound
+	}
+	if playerID != match.Player1ID && playerID != match.Player2ID {
+		return nil, ErrNotInMatch
+	}
+	// Only submissions made while the match is played count
+	if match.Status != MatchActive || match.Deadline != nil && time.Now().After(*match.Deadline) {
+		return nil, ErrMatchNotActive
+	}
+	problem, err := s.matchProblem(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if problem.Signature != nil && !HasHarness(language) {
+		return nil, fmt.Errorf("%w: function problems are not available in %s", ErrUnsupportedLanguage, language)
+	}
+
 	// Create submission record
 	submission := &database.Submission{
 		ID:       uuid.New(),
@@ -65,258 +211,390 @@ func (s *JudgeService) SubmitCode(ctx context.Context, matchID, playerID uuid.UU
 		PlayerID: playerID,
 		Code:     code,
 		Language: language,
-		Status:   "running",
+		Mode:     mode,
+		Status:   "pending",
 	}
 
 	if err := s.db.Create(submission).Error; err != nil {
 		return nil, err
 	}
 
-	// Judge the code
-	result, err := s.judgeCode(code, language, testCases)
+	job, err := json.Marshal(&judgeJob{SubmissionID: submission.ID})
 	if err != nil {
+		return nil, err
+	}
+
+	if err := s.queue.Publish(ctx, job); err != nil {
 		submission.Status = "error"
 		submission.ErrorMsg = err.Error()
 		s.db.Save(submission)
 		return nil, err
 	}
 
-	// Update submission with results
-	submission.Status = result.Status
-	submission.Score = result.Score
-	submission.Runtime = result.Runtime
-	submission.ErrorMsg = result.ErrorMsg
-	s.db.Save(submission)
-
-	return result, nil
+	return newSubmissionData(submission), nil
 }
 
-// judgeCode executes the code and validates against test cases
-func (s *JudgeService) judgeCode(code, language string, testCases []TestCase) (*JudgeResult, error) {
-	switch language {
-	case "go":
-		return s.judgeGoCode(code, testCases)
-	case "python":
-		return s.judgePythonCode(code, testCases)
-	case "javascript":
-		return s.judgeJavaScriptCode(code, testCases)
-	default:
-		return nil, fmt.Errorf("unsupported language: %s", language)
+// RunCustomInput runs code once on input supplied by the player. Nothing is
+// stored and the output is not compared against anything, so it never
+// affects scores. If matchID is set the player must be in the match, its
+// problem's limits apply, and for function problems the input is the
+// function's arguments.
+func (s *JudgeService) RunCustomInput(ctx context.Context, matchID, playerID uuid.UUID, code, language, input string) (*RunResult, error) {
+	if !s.languages.Has(language) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
 	}
-}
 
-// judgeGoCode judges Go code
-func (s *JudgeService) judgeGoCode(code string, testCases []TestCase) (*JudgeResult, error) {
-	// Create temporary directory
-	tempDir, err := os.MkdirTemp("", "judge_go_*")
+	var problem *ProblemData
+	var limits Limits
+	if matchID != uuid.Nil {
+		var match database.Match
+		if err := s.db.First(&match, "id = ?", matchID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		if playerID != match.Player1ID && playerID != match.Player2ID {
+			return nil, ErrNotInMatch
+		}
+
+		p, err := s.matchProblem(matchID)
+		if err != nil && !errors.Is(err, ErrNoProblem) {
+			return nil, err
+		}
+		if p != nil {
+			problem = p
+			limits = p.Limits
+		}
+	}
+
+	stdin, err := testInput(problem, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	source, err := prepareSource(problem, language, code)
 	if err != nil {
 		return nil, err
 	}
-	defer os.RemoveAll(tempDir)
 
-	// Write code to file
-	codeFile := filepath.Join(tempDir, "main.go")
-	if err := os.WriteFile(codeFile, []byte(code), 0644); err != nil {
+	prog, failure, err := s.prepareProgram(ctx, source, language)
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		return &RunResult{Verdict: failure.Verdict, Stderr: failure.ErrorMsg}, nil
+	}
+	defer prog.cleanup()
+
+	runResult, err := s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   prog.runner.RunCommand(),
+		Dir:    prog.dir,
+		Env:    prog.runner.Env(),
+		Stdin:  strings.NewReader(stdin),
+		Limits: limits.Sandbox(prog.runner.DefaultLimits()),
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Compile Go code
-	compileCmd := exec.Command("go", "build", "-o", filepath.Join(tempDir, "main"), codeFile)
-	compileOutput, err := compileCmd.CombinedOutput()
+	return &RunResult{
+		Verdict:  runVerdict(runResult),
+		Stdout:   string(runResult.Stdout),
+		Stderr:   string(runResult.Stderr),
+		ExitCode: runResult.ExitCode,
+		Signal:   signalName(runResult.Signal),
+		Runtime:  int(runResult.CPUTime.Milliseconds()),
+		WallTime: int(runResult.WallTime.Milliseconds()),
+		Memory:   int(runResult.PeakMemory >> 10),
+	}, nil
+}
+
+// matchProblem returns the problem assigned to a match
+func (s *JudgeService) matchProblem(matchID uuid.UUID) (*ProblemData, error) {
+	var match database.Match
+	if err := s.db.Preload("Problem").First(&match, "id = ?", matchID).Error; err != nil {
+		return nil, err
+	}
+	if match.ProblemID == uuid.Nil {
+		return nil, ErrNoProblem
+	}
+	return newProblemData(&match.Problem)
+}
+
+// ProgressFunc is called after each test case finishes
+type ProgressFunc func(done int, result TestCaseResult)
+
+// program is submitted code ready to run in its temporary directory
+type program struct {
+	dir    string
+	runner LanguageRunner
+}
+
+func (p *program) cleanup() {
+	os.RemoveAll(p.dir)
+}
+
+// judgeCode executes the code and validates it against the problem's test cases
+// in the given mode, ModeRunAll or ModeStopOnFailure
+func (s *JudgeService) judgeCode(ctx context.Context, code, language string, problem *ProblemData, mode string, progress ProgressFunc) (*JudgeResult, error) {
+	source, err := prepareSource(problem, language, code)
 	if err != nil {
-		return &JudgeResult{
-			Status:   "error",
-			Score:    0,
-			ErrorMsg: fmt.Sprintf("Compilation error: %s", string(compileOutput)),
-		}, nil
+		return nil, err
 	}
 
-	// Run test cases
-	var testCaseResults []TestCaseResult
-	passedCount := 0
-	totalRuntime := 0
+	prog, failure, err := s.prepareProgram(ctx, source, language)
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		return failure, nil
+	}
+	defer prog.cleanup()
 
-	for _, testCase := range testCases {
-		start := time.Now()
+	return s.runTestCases(ctx, prog, problem, problem.Limits.Sandbox(prog.runner.DefaultLimits()), mode, progress)
+}
 
-		// Run the program with test input
-		runCmd := exec.Command(filepath.Join(tempDir, "main"))
-		runCmd.Stdin = strings.NewReader(testCase.Input)
+// prepareProgram writes the code to a temporary directory and compiles it
+// if the language needs it. A non-nil JudgeResult reports a compilation error.
+func (s *JudgeService) prepareProgram(ctx context.Context, code, language string) (*program, *JudgeResult, error) {
+	runner, ok := s.languages.Get(language)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
+	}
 
-		output, _ := runCmd.CombinedOutput()
-		runtime := int(time.Since(start).Milliseconds())
-		totalRuntime += runtime
+	// Create temporary directory
+	tempDir, err := os.MkdirTemp("", "judge_"+runner.Name()+"_*")
+	if err != nil {
+		return nil, nil, err
+	}
+	prog := &program{dir: tempDir, runner: runner}
+
+	// Write code to file
+	codeFile := filepath.Join(tempDir, runner.SourceFile())
+	if err := os.WriteFile(codeFile, []byte(code), 0644); err != nil {
+		prog.cleanup()
+		return nil, nil, err
+	}
 
-		actual := strings.TrimSpace(string(output))
-		expected := strings.TrimSpace(testCase.Output)
+	compileCommand := runner.CompileCommand()
+	if len(compileCommand) == 0 {
+		return prog, nil, nil
+	}
 
-		passed := actual == expected
-		if passed {
-			passedCount++
+	// Reuse an earlier build of the same code
+	var cacheKey string
+	if s.cache != nil {
+		cacheKey = compileKey(runner, code)
+		if s.cache.Restore(cacheKey, tempDir) {
+			return prog, nil, nil
 		}
+	}
 
-		testCaseResults = append(testCaseResults, TestCaseResult{
-			Input:    testCase.Input,
-			Expected: expected,
-			Actual:   actual,
-			Passed:   passed,
-			Runtime:  runtime,
-		})
+	// Compile code
+	compileResult, err := s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   compileCommand,
+		Dir:    tempDir,
+		Env:    runner.Env(),
+		Limits: sandbox.CompileLimits,
+		Mounts: buildCacheMounts(runner),
+	})
+	if err != nil {
+		prog.cleanup()
+		return nil, nil, err
+	}
+	if !compileResult.Succeeded() {
+		prog.cleanup()
+		return nil, compilationError(compileResult), nil
 	}
 
-	// Calculate score
-	score := (passedCount * 100) / len(testCases)
-	status := "passed"
-	if score < 100 {
-		status = "failed"
+	if s.cache != nil {
+		if err := s.cache.Store(cacheKey, tempDir); err != nil {
+			log.Printf("Compile cache: store %s build: %v", runner.Name(), err)
+		}
 	}
 
-	return &JudgeResult{
-		Status:    status,
-		Score:     score,
-		Runtime:   totalRuntime,
-		TestCases: testCaseResults,
-	}, nil
+	return prog, nil, nil
 }
 
-// judgePythonCode judges Python code
-func (s *JudgeService) judgePythonCode(code string, testCases []TestCase) (*JudgeResult, error) {
-	// Create temporary directory
-	tempDir, err := os.MkdirTemp("", "judge_python_*")
+// runTestCases runs the prepared program against the test cases and assigns
+// verdicts. Tests run in parallel up to the service's limit; results are in
+// test order either way.
+func (s *JudgeService) runTestCases(ctx context.Context, prog *program, problem *ProblemData, limits sandbox.Limits, mode string, progress ProgressFunc) (*JudgeResult, error) {
+	judge, err := s.newTestJudge(ctx, prog, problem, limits)
 	if err != nil {
 		return nil, err
 	}
-	defer os.RemoveAll(tempDir)
+	defer judge.close()
 
-	// Write code to file
-	codeFile := filepath.Join(tempDir, "main.py")
-	if err := os.WriteFile(codeFile, []byte(code), 0644); err != nil {
+	outcomes, err := judge.runTests(ctx, mode, s.testParallelism, progress)
+	if err != nil {
 		return nil, err
 	}
 
-	// Run test cases
 	var testCaseResults []TestCaseResult
-	passedCount := 0
+	earnedWeight := 0 // sum of weight * awarded percentage
+	totalWeight := 0
 	totalRuntime := 0
+	peakMemory := 0
+	verdict := VerdictAccepted
+	errorMsg := ""
 
-	for _, testCase := range testCases {
-		start := time.Now()
-
-		// Run Python code with test input
-		runCmd := exec.Command("python3", codeFile)
-		runCmd.Stdin = strings.NewReader(testCase.Input)
+	for i, testCase := range problem.TestCases {
+		testCaseResult := outcomes[i].result
 
-		output, err := runCmd.CombinedOutput()
-		if err != nil {
-			return &JudgeResult{
-				Status:   "error",
-				Score:    0,
-				ErrorMsg: fmt.Sprintf("Runtime error: %s", string(output)),
-			}, nil
+		totalRuntime += testCaseResult.Runtime
+		if testCaseResult.Memory > peakMemory {
+			peakMemory = testCaseResult.Memory
 		}
-		runtime := int(time.Since(start).Milliseconds())
-		totalRuntime += runtime
-
-		actual := strings.TrimSpace(string(output))
-		expected := strings.TrimSpace(testCase.Output)
 
-		passed := actual == expected
-		if passed {
-			passedCount++
+		weight := testCase.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		totalWeight += weight
+		earnedWeight += weight * testCaseResult.Score
+
+		if !testCaseResult.Passed && verdict == VerdictAccepted {
+			// The first failing test decides the overall verdict
+			verdict = testCaseResult.Verdict
+			errorMsg = verdictMessage(i, testCaseResult.Verdict, outcomes[i].run, testCase.Hidden)
+			if testCaseResult.Message != "" && !testCase.Hidden {
+				errorMsg += ": " + testCaseResult.Message
+			}
 		}
 
-		testCaseResults = append(testCaseResults, TestCaseResult{
-			Input:    testCase.Input,
-			Expected: expected,
-			Actual:   actual,
-			Passed:   passed,
-			Runtime:  runtime,
-		})
+		testCaseResults = append(testCaseResults, *testCaseResult)
 	}
 
 	// Calculate score
-	score := (passedCount * 100) / len(testCases)
-	status := "passed"
-	if score < 100 {
-		status = "failed"
+	score := 0
+	if totalWeight > 0 {
+		score = earnedWeight / totalWeight
+	}
+	var subtasks []SubtaskResult
+	if len(problem.Subtasks) > 0 {
+		subtasks, score = scoreSubtasks(problem.Subtasks, problem.TestCases, testCaseResults)
 	}
 
 	return &JudgeResult{
-		Status:    status,
+		Verdict:   verdict,
 		Score:     score,
 		Runtime:   totalRuntime,
+		Memory:    peakMemory,
+		ErrorMsg:  errorMsg,
 		TestCases: testCaseResults,
+		Subtasks:  subtasks,
 	}, nil
 }
 
-// judgeJavaScriptCode judges JavaScript code
-func (s *JudgeService) judgeJavaScriptCode(code string, testCases []TestCase) (*JudgeResult, error) {
-	// Create temporary directory
-	tempDir, err := os.MkdirTemp("", "judge_js_*")
-	if err != nil {
-		return nil, err
+// testJudge runs single test cases of one submission
+type testJudge struct {
+	s          *JudgeService
+	problem    *ProblemData
+	prog       *program
+	limits     sandbox.Limits
+	checker    outputChecker
+	interactor *program // interactive problems only
+	cleanup    []func()
+}
+
+// newTestJudge prepares the problem's checker or interactor; call close when done
+func (s *JudgeService) newTestJudge(ctx context.Context, prog *program, problem *ProblemData, limits sandbox.Limits) (*testJudge, error) {
+	judge := &testJudge{s: s, problem: problem, prog: prog, limits: limits}
+
+	if problem.IsInteractive() {
+		interactor, failure, err := s.prepareProgram(ctx, problem.Interactor.Code, problem.Interactor.Language)
+		if err != nil {
+			return nil, fmt.Errorf("interactor: %w", err)
+		}
+		if failure != nil {
+			return nil, fmt.Errorf("interactor does not compile: %s", failure.ErrorMsg)
+		}
+		judge.interactor = interactor
+		judge.cleanup = append(judge.cleanup, interactor.cleanup)
+		return judge, nil
 	}
-	defer os.RemoveAll(tempDir)
 
-	// Write code to file
-	codeFile := filepath.Join(tempDir, "main.js")
-	if err := os.WriteFile(codeFile, []byte(code), 0644); err != nil {
+	checker, closeChecker, err := s.newChecker(ctx, problem)
+	if err != nil {
 		return nil, err
 	}
+	judge.checker = checker
+	judge.cleanup = append(judge.cleanup, closeChecker)
+	return judge, nil
+}
 
-	// Run test cases
-	var testCaseResults []TestCaseResult
-	passedCount := 0
-	totalRuntime := 0
-
-	for _, testCase := range testCases {
-		start := time.Now()
+func (j *testJudge) close() {
+	for _, cleanup := range j.cleanup {
+		cleanup()
+	}
+}
 
-		// Run Node.js code with test input
-		runCmd := exec.Command("node", codeFile)
-		runCmd.Stdin = strings.NewReader(testCase.Input)
+// runTest judges one test case. The sandbox result of the player's program
+// is returned as well for building error messages.
+func (j *testJudge) runTest(ctx context.Context, index int, testCase TestCase) (*TestCaseResult, *sandbox.Result, error) {
+	var runResult *sandbox.Result
+	var check *checkResult
+	var err error
+	if j.interactor != nil {
+		runResult, check, err = j.runInteractive(ctx, index, testCase)
+	} else {
+		runResult, check, err = j.runStandard(ctx, testCase)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-		output, err := runCmd.CombinedOutput()
-		if err != nil {
-			return &JudgeResult{
-				Status:   "error",
-				Score:    0,
-				ErrorMsg: fmt.Sprintf("Runtime error: %s", string(output)),
-			}, nil
-		}
-		runtime := int(time.Since(start).Milliseconds())
-		totalRuntime += runtime
+	return &TestCaseResult{
+		Input:    testCase.Input,
+		Expected: strings.TrimSpace(testCase.Output),
+		Actual:   strings.TrimSpace(string(runResult.Stdout)),
+		Passed:   check.Verdict == VerdictAccepted,
+		Runtime:  int(runResult.CPUTime.Milliseconds()),
+		Memory:   int(runResult.PeakMemory >> 10),
+		Verdict:  check.Verdict,
+		ExitCode: runResult.ExitCode,
+		Signal:   signalName(runResult.Signal),
+		Hidden:   testCase.Hidden,
+		Group:    testCase.Group,
+		Score:    check.Score,
+		Message:  check.Message,
+	}, runResult, nil
+}
 
-		actual := strings.TrimSpace(string(output))
-		expected := strings.TrimSpace(testCase.Output)
+// runStandard feeds the test input to the program and checks its output
+func (j *testJudge) runStandard(ctx context.Context, testCase TestCase) (*sandbox.Result, *checkResult, error) {
+	runResult, err := j.s.runOnInput(ctx, j.prog, j.problem, j.limits, testCase.Input)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		passed := actual == expected
-		if passed {
-			passedCount++
-		}
+	if verdict := runVerdict(runResult); verdict != VerdictAccepted {
+		return runResult, &checkResult{Verdict: verdict}, nil
+	}
 
-		testCaseResults = append(testCaseResults, TestCaseResult{
-			Input:    testCase.Input,
-			Expected: expected,
-			Actual:   actual,
-			Passed:   passed,
-			Runtime:  runtime,
-		})
+	actual := strings.TrimSpace(string(runResult.Stdout))
+	expected := strings.TrimSpace(testCase.Output)
+	check, err := j.checker.check(ctx, testCase.Input, expected, actual)
+	if err != nil {
+		return nil, nil, err
 	}
+	return runResult, check, nil
+}
 
-	// Calculate score
-	score := (passedCount * 100) / len(testCases)
-	status := "passed"
-	if score < 100 {
-		status = "failed"
+// runOnInput runs a prepared program with a test input of the problem
+func (s *JudgeService) runOnInput(ctx context.Context, prog *program, problem *ProblemData, limits sandbox.Limits, input string) (*sandbox.Result, error) {
+	stdin, err := testInput(problem, input)
+	if err != nil {
+		return nil, err
 	}
 
-	return &JudgeResult{
-		Status:    status,
-		Score:     score,
-		Runtime:   totalRuntime,
-		TestCases: testCaseResults,
-	}, nil
+	return s.sandbox.Run(ctx, &sandbox.Command{
+		Args:   prog.runner.RunCommand(),
+		Dir:    prog.dir,
+		Env:    prog.runner.Env(),
+		Stdin:  strings.NewReader(stdin),
+		Limits: limits,
+	})
 }
 
 // GetSubmission returns a submission by ID
@@ -326,18 +604,40 @@ func (s *JudgeService) GetSubmission(id uuid.UUID) (*SubmissionData, error) {
 		return nil, err
 	}
 
-	return &SubmissionData{
-		ID:        submission.ID,
-		MatchID:   submission.MatchID,
-		PlayerID:  submission.PlayerID,
-		Code:      submission.Code,
-		Language:  submission.Language,
-		Status:    submission.Status,
-		Score:     submission.Score,
-		Runtime:   submission.Runtime,
-		ErrorMsg:  submission.ErrorMsg,
-		CreatedAt: submission.CreatedAt,
-	}, nil
+	return newSubmissionData(&submission), nil
+}
+
+// watchInterval is how often WatchSubmission looks for progress
+const watchInterval = 250 * time.Millisecond
+
+// WatchSubmission calls fn with the submission now and whenever its status
+// or progress changes, until it is judged, fn fails or ctx is cancelled
+func (s *JudgeService) WatchSubmission(ctx context.Context, id uuid.UUID, fn func(*SubmissionData) error) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var last *SubmissionData
+	for {
+		submission, err := s.GetSubmission(id)
+		if err != nil {
+			return err
+		}
+		if last == nil || submission.Status != last.Status || submission.Progress != last.Progress {
+			if err := fn(submission); err != nil {
+				return err
+			}
+			last = submission
+		}
+		if submission.Status == "done" || submission.Status == "error" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
 // GetMatchSubmissions returns all submissions for a match
@@ -348,20 +648,57 @@ func (s *JudgeService) GetMatchSubmissions(matchID uuid.UUID) ([]*SubmissionData
 	}
 
 	result := make([]*SubmissionData, len(submissions))
-	for i, submission := range submissions {
-		result[i] = &SubmissionData{
-			ID:        submission.ID,
-			MatchID:   submission.MatchID,
-			PlayerID:  submission.PlayerID,
-			Code:      submission.Code,
-			Language:  submission.Language,
-			Status:    submission.Status,
-			Score:     submission.Score,
-			Runtime:   submission.Runtime,
-			ErrorMsg:  submission.ErrorMsg,
-			CreatedAt: submission.CreatedAt,
-		}
+	for i := range submissions {
+		result[i] = newSubmissionData(&submissions[i])
 	}
 
 	return result, nil
 }
+
+// newSubmissionData converts a stored submission into its API representation
+func newSubmissionData(submission *database.Submission) *SubmissionData {
+	var testResults []TestCaseResult
+	json.Unmarshal([]byte(submission.TestResults), &testResults)
+	var subtasks []SubtaskResult
+	json.Unmarshal([]byte(submission.Subtasks), &subtasks)
+	var analysis *Analysis
+	if submission.Analysis != "" {
+		json.Unmarshal([]byte(submission.Analysis), &analysis)
+	}
+
+	return &SubmissionData{
+		ID:          submission.ID,
+		MatchID:     submission.MatchID,
+		PlayerID:    submission.PlayerID,
+		Code:        submission.Code,
+		Language:    submission.Language,
+		Mode:        submission.Mode,
+		Status:      submission.Status,
+		Verdict:     Verdict(submission.Verdict),
+		Score:       submission.Score,
+		Runtime:     submission.Runtime,
+		Memory:      submission.Memory,
+		ErrorMsg:    submission.ErrorMsg,
+		TestResults: RedactHiddenResults(testResults),
+		Subtasks:    subtasks,
+		Progress:    Progress{Done: submission.TestsDone, Total: submission.TestsTotal},
+		Analysis:    analysis,
+		CreatedAt:   submission.CreatedAt,
+	}
+}
+
+// RedactHiddenResults strips input, expected and actual output from hidden
+// test results so players only learn whether they passed
+func RedactHiddenResults(results []TestCaseResult) []TestCaseResult {
+	redacted := make([]TestCaseResult, len(results))
+	for i, result := range results {
+		if result.Hidden {
+			result.Input = ""
+			result.Expected = ""
+			result.Actual = ""
+			result.Message = ""
+		}
+		redacted[i] = result
+	}
+	return redacted
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"coderoulette/internal/sandbox"
+)
+
+// LanguageRunner describes how to build and run programs written in one language
+type LanguageRunner interface {
+	Name() string
+	DisplayName() string
+	Version() string
+	SourceFile() string       // file the submitted code is written to
+	CompileCommand() []string // nil for interpreted languages
+	RunCommand() []string
+	Env() []string // environment for both compiling and running
+	DefaultLimits() sandbox.Limits
+
+	// BuildCache returns a host directory shared by all compilations and
+	// where it is mounted in the sandbox, or empty strings for none
+	BuildCache() (dir, mount string)
+	WarmCommand() []string // trusted command that fills the build cache
+
+	// Analyzers are linters and formatters giving feedback on judged code
+	Analyzers() []AnalyzerConfig
+}
+
+// LanguageInfo is the public description of a registered language
+type LanguageInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Version     string `json:"version"`
+	SourceFile  string `json:"source_file"`
+	Compiled    bool   `json:"compiled"`
+}
+
+// LanguageConfig configures a command based language runner
+type LanguageConfig struct {
+	Name           string   `json:"name"`
+	DisplayName    string   `json:"display_name"`
+	Version        string   `json:"version"`
+	VersionCommand []string `json:"version_command"` // run on load when version is empty
+	SourceFile     string   `json:"source_file"`
+	Compile        []string `json:"compile"`
+	Run            []string `json:"run"`
+	Env            []string `json:"env"`
+	TimeLimit      int      `json:"time_limit"`    // in milliseconds
+	MemoryLimit    int      `json:"memory_limit"`  // in megabytes
+	ProcessLimit   int      `json:"process_limit"` // processes and threads
+	OutputLimit    int      `json:"output_limit"`  // in kilobytes
+
+	// Compilations see the build cache as a throwaway overlay, only the
+	// warm command run when a judge starts can write to it
+	BuildCache      string   `json:"build_cache"`
+	BuildCacheMount string   `json:"build_cache_mount"`
+	WarmCommand     []string `json:"warm_command"`
+
+	Analyzers []AnalyzerConfig `json:"analyzers"`
+}
+
+// commandRunner is a LanguageRunner driven by a LanguageConfig
+type commandRunner struct {
+	config LanguageConfig
+	limits sandbox.Limits
+}
+
+func newCommandRunner(config LanguageConfig) (*commandRunner, error) {
+	if config.Name == "" || config.SourceFile == "" || len(config.Run) == 0 {
+		return nil, fmt.Errorf("language %q: name, source_file and run are required", config.Name)
+	}
+	if config.DisplayName == "" {
+		config.DisplayName = config.Name
+	}
+	for i, analyzer := range config.Analyzers {
+		if analyzer.Name == "" || len(analyzer.Command) == 0 {
+			return nil, fmt.Errorf("language %q: analyzers need a name and a command", config.Name)
+		}
+		switch analyzer.Format {
+		case "":
+			config.Analyzers[i].Format = AnalyzerLines
+		case AnalyzerLines, AnalyzerDiff:
+		default:
+			return nil, fmt.Errorf("language %q: analyzer %s: unknown format %q", config.Name, analyzer.Name, analyzer.Format)
+		}
+	}
+	if config.Version == "" && len(config.VersionCommand) > 0 {
+		config.Version = detectVersion(config.VersionCommand)
+	}
+
+	limits := Limits{
+		TimeLimit:    config.TimeLimit,
+		MemoryLimit:  config.MemoryLimit,
+		ProcessLimit: config.ProcessLimit,
+		OutputLimit:  config.OutputLimit,
+	}
+
+	return &commandRunner{
+		config: config,
+		limits: limits.Sandbox(sandbox.DefaultLimits),
+	}, nil
+}
+
+func (r *commandRunner) Name() string                  { return r.config.Name }
+func (r *commandRunner) DisplayName() string           { return r.config.DisplayName }
+func (r *commandRunner) Version() string               { return r.config.Version }
+func (r *commandRunner) SourceFile() string            { return r.config.SourceFile }
+func (r *commandRunner) CompileCommand() []string      { return r.config.Compile }
+func (r *commandRunner) RunCommand() []string          { return r.config.Run }
+func (r *commandRunner) Env() []string                 { return r.config.Env }
+func (r *commandRunner) DefaultLimits() sandbox.Limits { return r.limits }
+func (r *commandRunner) WarmCommand() []string         { return r.config.WarmCommand }
+func (r *commandRunner) Analyzers() []AnalyzerConfig   { return r.config.Analyzers }
+
+func (r *commandRunner) BuildCache() (string, string) {
+	if r.config.BuildCache == "" || r.config.BuildCacheMount == "" {
+		return "", ""
+	}
+	return r.config.BuildCache, r.config.BuildCacheMount
+}
+
+// detectVersion runs a trusted toolchain command and returns the first line of its output
+func detectVersion(command []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return "unknown"
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return line
+}
+
+// LanguageRegistry holds the languages the judge accepts
+type LanguageRegistry struct {
+	mu      sync.RWMutex
+	runners map[string]LanguageRunner
+}
+
+func NewLanguageRegistry() *LanguageRegistry {
+	return &LanguageRegistry{runners: make(map[string]LanguageRunner)}
+}
+
+// Register adds or replaces a language runner
+func (r *LanguageRegistry) Register(runner LanguageRunner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runners[runner.Name()] = runner
+}
+
+// Get returns the runner for a language
+func (r *LanguageRegistry) Get(name string) (LanguageRunner, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	runner, ok := r.runners[name]
+	return runner, ok
+}
+
+// Has reports whether a language is registered
+func (r *LanguageRegistry) Has(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// List describes all registered languages, sorted by name
+func (r *LanguageRegistry) List() []LanguageInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	languages := make([]LanguageInfo, 0, len(r.runners))
+	for _, runner := range r.runners {
+		languages = append(languages, LanguageInfo{
+			Name:        runner.Name(),
+			DisplayName: runner.DisplayName(),
+			Version:     runner.Version(),
+			SourceFile:  runner.SourceFile(),
+			Compiled:    len(runner.CompileCommand()) > 0,
+		})
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].Name < languages[j].Name
+	})
+	return languages
+}
+
+// Runners returns all registered runners, sorted by name
+func (r *LanguageRegistry) Runners() []LanguageRunner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	runners := make([]LanguageRunner, 0, len(r.runners))
+	for _, runner := range r.runners {
+		runners = append(runners, runner)
+	}
+	sort.Slice(runners, func(i, j int) bool {
+		return runners[i].Name() < runners[j].Name()
+	})
+	return runners
+}
+
+// LoadLanguageRegistry builds a registry from a JSON file of the form
+// {"languages": [LanguageConfig, ...]}
+func LoadLanguageRegistry(path string) (*LanguageRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Languages []LanguageConfig `json:"languages"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	registry := NewLanguageRegistry()
+	for _, config := range file.Languages {
+		runner, err := newCommandRunner(config)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(runner)
+	}
+	return registry, nil
+}
+
+// DefaultLanguageRegistry returns the built-in Go, Python and JavaScript runners
+func DefaultLanguageRegistry() *LanguageRegistry {
+	registry := NewLanguageRegistry()
+	for _, config := range defaultLanguages {
+		runner, err := newCommandRunner(config)
+		if err != nil {
+			panic(err)
+		}
+		registry.Register(runner)
+	}
+	return registry
+}
+
+// defaultLanguages are used when no languages config file is available
+var defaultLanguages = []LanguageConfig{
+	{
+		Name:           "go",
+		DisplayName:    "Go",
+		VersionCommand: []string{"go", "version"},
+		SourceFile:     "main.go",
+		Compile:        []string{"go", "build", "-o", "main", "main.go"},
+		Run:            []string{"./main"},
+		// Keep the Go toolchain inside the sandbox's writable tmpfs and offline
+		Env: []string{
+			"PATH=/usr/local/go/bin:/usr/local/bin:/usr/bin:/bin",
+			"HOME=/tmp",
+			"GOCACHE=/tmp/gocache",
+			"GOPATH=/tmp/gopath",
+			"GOTOOLCHAIN=local",
+			"GOPROXY=off",
+			"CGO_ENABLED=0",
+		},
+		// Keep the standard library compiled between builds
+		BuildCache:      "/var/cache/coderoulette/go-build",
+		BuildCacheMount: "/tmp/gocache",
+		WarmCommand:     []string{"go", "build", "std"},
+		Analyzers: []AnalyzerConfig{
+			{Name: "vet", Command: []string{"go", "vet", "main.go"}},
+			{Name: "gofmt", Command: []string{"gofmt", "-d", "main.go"}, Format: AnalyzerDiff, Message: "code is not gofmt-formatted"},
+		},
+	},
+	{
+		Name:           "python",
+		DisplayName:    "Python 3",
+		VersionCommand: []string{"python3", "--version"},
+		SourceFile:     "main.py",
+		Run:            []string{"python3", "main.py"},
+		Analyzers: []AnalyzerConfig{
+			{Name: "pyflakes", Command: []string{"python3", "-m", "pyflakes", "main.py"}},
+		},
+	},
+	{
+		Name:           "javascript",
+		DisplayName:    "JavaScript (Node.js)",
+		VersionCommand: []string{"node", "--version"},
+		SourceFile:     "main.js",
+		Run:            []string{"node", "main.js"},
+	},
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"coderoulette/internal/sandbox"
+)
+
+// checkResult is the judgement of one test's output
+type checkResult struct {
+	Verdict Verdict
+	Score   int    // percentage of the test's weight awarded, 0-100
+	Message string // optional explanation from a custom checker
+}
+
+// outputChecker decides whether the output of a test is correct
+type outputChecker interface {
+	check(ctx context.Context, input, expected, actual string) (*checkResult, error)
+}
+
+// compareFunc is a built-in all-or-nothing comparison
+type compareFunc func(expected, actual string) bool
+
+func (f compareFunc) check(ctx context.Context, input, expected, actual string) (*checkResult, error) {
+	if f(expected, actual) {
+		return &checkResult{Verdict: VerdictAccepted, Score: 100}, nil
+	}
+	return &checkResult{Verdict: VerdictWrongAnswer}, nil
+}
+
+// newChecker returns the output checker of a problem. Custom checkers are
+// compiled first; call the returned cleanup when judging is done.
+func (s *JudgeService) newChecker(ctx context.Context, problem *ProblemData) (outputChecker, func(), error) {
+	checker := problem.Checker
+	if checker == nil {
+		checker = &Checker{Mode: CompareExact}
+	}
+	function := problem.Signature != nil
+
+	switch checker.Mode {
+	case "", CompareExact:
+		if function {
+			return compareFunc(jsonEqual(0, 0)), func() {}, nil
+		}
+		return compareFunc(compareExact), func() {}, nil
+	case CompareTokens:
+		if function {
+			return compareFunc(jsonEqual(0, 0)), func() {}, nil
+		}
+		return compareFunc(compareTokens), func() {}, nil
+	case CompareFloat:
+		abs, rel := checker.Epsilons()
+		if function {
+			return compareFunc(jsonEqual(abs, rel)), func() {}, nil
+		}
+		return compareFunc(compareFloats(abs, rel)), func() {}, nil
+	case CompareUnordered:
+		if function {
+			return compareFunc(compareJSONUnordered), func() {}, nil
+		}
+		return compareFunc(compareUnorderedLines), func() {}, nil
+	case CompareCustom:
+		prog, failure, err := s.prepareProgram(ctx, checker.Code, checker.Language)
+		if err != nil {
+			return nil, nil, fmt.Errorf("checker: %w", err)
+		}
+		if failure != nil {
+			return nil, nil, fmt.Errorf("checker does not compile: %s", failure.ErrorMsg)
+		}
+		return &customChecker{sandbox: s.sandbox, prog: prog}, prog.cleanup, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown comparison mode %q", checker.Mode)
+	}
+}
+
+func compareExact(expected, actual string) bool {
+	return strings.TrimSpace(expected) == strings.TrimSpace(actual)
+}
+
+func compareTokens(expected, actual string) bool {
+	return reflect.DeepEqual(strings.Fields(expected), strings.Fields(actual))
+}
+
+// compareFloats compares token by token, numbers within either tolerance
+func compareFloats(abs, rel float64) compareFunc {
+	return func(expected, actual string) bool {
+		expectedTokens := strings.Fields(expected)
+		actualTokens := strings.Fields(actual)
+		if len(expectedTokens) != len(actualTokens) {
+			return false
+		}
+		for i := range expectedTokens {
+			if expectedTokens[i] == actualTokens[i] {
+				continue
+			}
+			e, err1 := strconv.ParseFloat(expectedTokens[i], 64)
+			a, err2 := strconv.ParseFloat(actualTokens[i], 64)
+			if err1 != nil || err2 != nil || !floatsClose(e, a, abs, rel) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func floatsClose(expected, actual, abs, rel float64) bool {
+	// NaN and infinities only match themselves, a relative tolerance of an
+	// infinity would accept anything
+	if math.IsNaN(expected) || math.IsNaN(actual) {
+		return math.IsNaN(expected) && math.IsNaN(actual)
+	}
+	if math.IsInf(expected, 0) || math.IsInf(actual, 0) {
+		return expected == actual
+	}
+	diff := math.Abs(expected - actual)
+	return diff <= abs || diff <= rel*math.Abs(expected)
+}
+
+// compareUnorderedLines compares the non-empty lines as multisets
+func compareUnorderedLines(expected, actual string) bool {
+	return reflect.DeepEqual(sortedLines(expected), sortedLines(actual))
+}
+
+func sortedLines(s string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// jsonEqual compares function results as JSON values, numbers within the
+// given tolerance
+func jsonEqual(abs, rel float64) compareFunc {
+	return func(expected, actual string) bool {
+		var e, a interface{}
+		if json.Unmarshal([]byte(expected), &e) != nil || json.Unmarshal([]byte(actual), &a) != nil {
+			return strings.TrimSpace(expected) == strings.TrimSpace(actual)
+		}
+		return jsonValuesEqual(e, a, abs, rel)
+	}
+}
+
+func jsonValuesEqual(expected, actual interface{}, abs, rel float64) bool {
+	switch e := expected.(type) {
+	case float64:
+		a, ok := actual.(float64)
+		return ok && (e == a || floatsClose(e, a, abs, rel))
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !jsonValuesEqual(e[i], a[i], abs, rel) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// compareJSONUnordered compares array results ignoring element order
+func compareJSONUnordered(expected, actual string) bool {
+	var e, a []json.RawMessage
+	if json.Unmarshal([]byte(expected), &e) != nil || json.Unmarshal([]byte(actual), &a) != nil {
+		return jsonEqual(0, 0)(expected, actual)
+	}
+	if len(e) != len(a) {
+		return false
+	}
+	canonical := func(items []json.RawMessage) []string {
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = canonicalJSON(string(item))
+		}
+		sort.Strings(values)
+		return values
+	}
+	return reflect.DeepEqual(canonical(e), canonical(a))
+}
+
+// customChecker runs a problem's checker program on every test
+type customChecker struct {
+	sandbox *sandbox.Sandbox
+	prog    *program
+	calls   atomic.Int64
+}
+
+func (c *customChecker) check(ctx context.Context, input, expected, actual string) (*checkResult, error) {
+	// Every call writes its own files next to the compiled checker so tests
+	// can be checked side by side
+	n := c.calls.Add(1)
+	names := []string{
+		fmt.Sprintf("input_%d.txt", n),
+		fmt.Sprintf("expected_%d.txt", n),
+		fmt.Sprintf("actual_%d.txt", n),
+	}
+	for i, content := range []string{input, expected, actual} {
+		path := filepath.Join(c.prog.dir, names[i])
+		defer os.Remove(path)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	args := append(append([]string{}, c.prog.runner.RunCommand()...), names...)
+	result, err := c.sandbox.Run(ctx, &sandbox.Command{
+		Args:   args,
+		Dir:    c.prog.dir,
+		Env:    c.prog.runner.Env(),
+		Limits: c.prog.runner.DefaultLimits(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Succeeded() {
+		return nil, fmt.Errorf("checker failed with exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+	return parseCheckerOutput(result.Stdout)
+}
+
+// parseCheckerOutput reads "AC|WA [score]" from the first line of a
+// checker's output, the remaining lines are its message
+func parseCheckerOutput(output []byte) (*checkResult, error) {
+	first, rest, _ := bytes.Cut(bytes.TrimSpace(output), []byte("\n"))
+	fields := strings.Fields(string(first))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("checker printed no verdict")
+	}
+
+	result := &checkResult{Message: strings.TrimSpace(string(rest))}
+	switch strings.ToUpper(fields[0]) {
+	case "AC":
+		result.Verdict = VerdictAccepted
+		result.Score = 100
+	case "WA":
+		result.Verdict = VerdictWrongAnswer
+	default:
+		return nil, fmt.Errorf("checker printed unknown verdict %q", fields[0])
+	}
+
+	if len(fields) > 1 {
+		score, err := strconv.Atoi(fields[1])
+		if err != nil || score < 0 || score > 100 {
+			return nil, fmt.Errorf("checker printed invalid score %q", fields[1])
+		}
+		result.Score = score
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloatsClose(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+
+	tests := []struct {
+		name             string
+		expected, actual float64
+		abs, rel         float64
+		want             bool
+	}{
+		{"equal", 1, 1, 0, 0, true},
+		{"at the absolute tolerance", 1, 1.5, 0.5, 0, true},
+		{"past the absolute tolerance", 1, 1.75, 0.5, 0, false},
+		{"at the relative tolerance", 4, 5, 0, 0.25, true},
+		{"past the relative tolerance", 4, 5.5, 0, 0.25, false},
+		{"relative to the expected value", 4, 5.25, 0, 0.25, false},
+		{"relative below the expected value", 5, 4, 0, 0.25, true},
+		{"negative values", -4, -5, 0, 0.25, true},
+		{"relative tolerance of zero", 0, 1e-300, 0, 0.25, false},
+		{"either tolerance is enough", 100, 110, 0.5, 0.1, true},
+		{"neither tolerance", 100, 111, 0.5, 0.1, false},
+		{"NaN matches NaN", nan, nan, 0, 0, true},
+		{"NaN does not match a number", nan, 1, 1, 1, false},
+		{"number does not match NaN", 1, nan, 1, 1, false},
+		{"infinity matches itself", inf, inf, 0, 0, true},
+		{"infinity does not match a number", inf, math.MaxFloat64, 0, 1, false},
+		{"number does not match infinity", math.MaxFloat64, inf, 0, 1, false},
+		{"opposite infinities", inf, -inf, 1, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := floatsClose(tt.expected, tt.actual, tt.abs, tt.rel); got != tt.want {
+				t.Errorf("floatsClose(%v, %v, %v, %v) = %v, want %v", tt.expected, tt.actual, tt.abs, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareFloats(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		want             bool
+	}{
+		{"within tolerance", "1.0 2.0", "1.0004 2", true},
+		{"outside tolerance", "1.0", "1.01", false},
+		{"whitespace differs", "1 2\n3", "1\n2   3\n", true},
+		{"fewer tokens", "1 2 3", "1 2", false},
+		{"more tokens", "1 2", "1 2 3", false},
+		{"both empty", "", "\n", true},
+		{"words around numbers", "yes 1", "yes 1.0001", true},
+		{"different words", "yes 1", "no 1", false},
+		{"word for a number", "1", "one", false},
+		{"same NaN token", "nan", "nan", true},
+		{"NaN spelled differently", "NaN", "nan", true},
+		{"NaN for a number", "1", "NaN", false},
+		{"infinity spelled differently", "inf", "+Inf", true},
+		{"infinity for a number", "inf", "1e308", false},
+		{"number for infinity", "1e308", "Inf", false},
+	}
+	compare := compareFloats(1e-3, 1e-3)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compare(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("compareFloats(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareUnorderedLines(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		want             bool
+	}{
+		{"same order", "a\nb\nc", "a\nb\nc", true},
+		{"other order", "a\nb\nc", "c\na\nb", true},
+		{"blank lines and spacing", "a\nb", "\n  b \n\na\n", true},
+		{"duplicates in any order", "a\na\nb", "a\nb\na", true},
+		{"missing duplicate", "a\na\nb", "a\nb", false},
+		{"extra duplicate", "a\nb", "a\nb\nb", false},
+		{"duplicate instead of a line", "a\nb", "a\na", false},
+		{"spacing inside a line", "a b", "a  b", false},
+		{"both empty", "", "\n\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareUnorderedLines(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("compareUnorderedLines(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	tests := []struct {
+		name             string
+		abs, rel         float64
+		expected, actual string
+		want             bool
+	}{
+		{"exact numbers", 0, 0, "3", "3.0", true},
+		{"numbers differ without tolerance", 0, 0, "0.3", "0.30000000000000004", false},
+		{"within absolute tolerance", 1e-6, 0, "0.3", "0.30000000000000004", true},
+		{"within relative tolerance", 0, 0.01, "[100, 200]", "[100.5, 199]", true},
+		{"outside tolerance", 0, 0.01, "[100, 200]", "[100, 203]", false},
+		{"nested arrays", 0.5, 0, "[[1, 2], [3]]", "[[1.25, 2], [2.75]]", true},
+		{"array lengths differ", 1, 1, "[1, 2]", "[1, 2, 3]", false},
+		{"number for a string", 1, 1, `"1"`, "1", false},
+		{"number for a bool", 1, 1, "1", "true", false},
+		{"objects compared exactly", 1, 1, `{"a": 1}`, `{"a": 1.5}`, false},
+		{"objects ignore key order", 0, 0, `{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, true},
+		{"invalid JSON compared as text", 0, 0, "not json", " not json\n", true},
+		{"invalid JSON differs", 1, 1, "1", "1 2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonEqual(tt.abs, tt.rel)(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("jsonEqual(%v, %v)(%q, %q) = %v, want %v", tt.abs, tt.rel, tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCheckerOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    checkResult
+		wantErr bool
+	}{
+		{"accepted", "AC\n", checkResult{Verdict: VerdictAccepted, Score: 100}, false},
+		{"wrong answer", "WA", checkResult{Verdict: VerdictWrongAnswer}, false},
+		{"lower case", "ac", checkResult{Verdict: VerdictAccepted, Score: 100}, false},
+		{"partial score", "WA 40", checkResult{Verdict: VerdictWrongAnswer, Score: 40}, false},
+		{"accepted with a score", "AC 75", checkResult{Verdict: VerdictAccepted, Score: 75}, false},
+		{"message", "\n WA 0\nline 3 differs\nexpected 7 \n", checkResult{Verdict: VerdictWrongAnswer, Message: "line 3 differs\nexpected 7"}, false},
+		{"extra fields ignored", "AC 100 points", checkResult{Verdict: VerdictAccepted, Score: 100}, false},
+		{"empty", "", checkResult{}, true},
+		{"only whitespace", " \n\t\n", checkResult{}, true},
+		{"unknown verdict", "OK", checkResult{}, true},
+		{"verdict not first", "score 1\nAC", checkResult{}, true},
+		{"score not a number", "WA half", checkResult{}, true},
+		{"fractional score", "WA 50.5", checkResult{}, true},
+		{"negative score", "WA -1", checkResult{}, true},
+		{"score above 100", "AC 101", checkResult{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCheckerOutput([]byte(tt.output))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCheckerOutput(%q) = %+v, want an error", tt.output, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCheckerOutput(%q): %v", tt.output, err)
+			}
+			if *got != tt.want {
+				t.Errorf("parseCheckerOutput(%q) = %+v, want %+v", tt.output, *got, tt.want)
+			}
+		})
+	}
+}
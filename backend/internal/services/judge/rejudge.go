@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RejudgeOptions controls a rejudge
+type RejudgeOptions struct {
+	Reason string `json:"reason"`
+	// Set the winners of completed matches again once all of their
+	// rejudged submissions are judged
+	RecomputeWinners bool `json:"recompute_winners"`
+}
+
+// RejudgeResult lists the submissions a rejudge queued
+type RejudgeResult struct {
+	Queued   []uuid.UUID `json:"queued"`
+	Failed   []uuid.UUID `json:"failed"`   // could not be queued, marked as errors
+	Deferred []uuid.UUID `json:"deferred"` // being judged, queued again once judged
+	Skipped  []uuid.UUID `json:"skipped"`  // still pending
+}
+
+// RejudgeSubmission judges a single submission again
+func (s *JudgeService) RejudgeSubmission(ctx context.Context, submissionID uuid.UUID, opts RejudgeOptions) (*RejudgeResult, error) {
+	var count int64
+	if err := s.db.Model(&database.Submission{}).Where("id = ?", submissionID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return s.rejudge(ctx, s.db.Where("id = ?", submissionID), opts)
+}
+
+// RejudgeMatch judges all submissions of a match again
+func (s *JudgeService) RejudgeMatch(ctx context.Context, matchID uuid.UUID, opts RejudgeOptions) (*RejudgeResult, error) {
+	var count int64
+	if err := s.db.Model(&database.Match{}).Where("id = ?", matchID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrMatchNotFound
+	}
+	return s.rejudge(ctx, s.db.Where("match_id = ?", matchID), opts)
+}
+
+// RejudgeProblem judges all submissions to matches of a problem again,
+// typically after its tests or checker were fixed
+func (s *JudgeService) RejudgeProblem(ctx context.Context, problemID uuid.UUID, opts RejudgeOptions) (*RejudgeResult, error) {
+	var count int64
+	if err := s.db.Model(&database.Problem{}).Where("id = ?", problemID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrProblemNotFound
+	}
+	matches := s.db.Model(&database.Match{}).Select("id").Where("problem_id = ?", problemID)
+	return s.rejudge(ctx, s.db.Where("match_id IN (?)", matches), opts)
+}
+
+// rejudge moves the current outcome of the selected submissions to their
+// history, resets them to pending and queues them again. Pending
+// submissions are left alone since they will be judged with the current
+// tests anyway, running ones are rejudged once their job finishes.
+func (s *JudgeService) rejudge(ctx context.Context, query *gorm.DB, opts RejudgeOptions) (*RejudgeResult, error) {
+	var submissions []database.Submission
+	if err := query.Order("created_at ASC").Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	result := &RejudgeResult{Queued: []uuid.UUID{}, Failed: []uuid.UUID{}, Deferred: []uuid.UUID{}, Skipped: []uuid.UUID{}}
+	for i := range submissions {
+		submission := &submissions[i]
+		if submission.Status == "running" {
+			deferred, err := s.deferRejudge(submission, opts)
+			if err != nil {
+				return result, err
+			}
+			if deferred {
+				result.Deferred = append(result.Deferred, submission.ID)
+				continue
+			}
+		}
+		if submission.Status == "pending" || submission.Status == "running" {
+			result.Skipped = append(result.Skipped, submission.ID)
+			continue
+		}
+
+		queued, err := s.requeueForRejudge(ctx, submission, opts)
+		if err != nil {
+			return result, err
+		}
+		if queued {
+			result.Queued = append(result.Queued, submission.ID)
+		} else {
+			result.Failed = append(result.Failed, submission.ID)
+		}
+	}
+	return result, nil
+}
+
+// deferRejudge asks the worker judging a submission to queue it again once
+// it is done. If the job finished in the meantime it reloads the
+// submission and returns false.
+func (s *JudgeService) deferRejudge(submission *database.Submission, opts RejudgeOptions) (bool, error) {
+	pending, err := json.Marshal(&opts)
+	if err != nil {
+		return false, err
+	}
+	update := s.db.Model(&database.Submission{}).
+		Where("id = ? AND status = ?", submission.ID, "running").
+		Update("pending_rejudge", string(pending))
+	if update.Error != nil {
+		return false, update.Error
+	}
+	if update.RowsAffected > 0 {
+		return true, nil
+	}
+	return false, s.db.First(submission, "id = ?", submission.ID).Error
+}
+
+// rejudgeIfRequested queues a submission again if a rejudge was requested
+// while it was being judged
+func (s *JudgeService) rejudgeIfRequested(ctx context.Context, submissionID uuid.UUID) error {
+	var submission database.Submission
+	if err := s.db.First(&submission, "id = ?", submissionID).Error; err != nil {
+		return err
+	}
+	if submission.PendingRejudge == "" {
+		return nil
+	}
+
+	// Only one worker takes the request, a newer one is left for later
+	update := s.db.Model(&database.Submission{}).
+		Where("id = ? AND pending_rejudge = ?", submission.ID, submission.PendingRejudge).
+		Update("pending_rejudge", "")
+	if update.Error != nil || update.RowsAffected == 0 {
+		return update.Error
+	}
+
+	var opts RejudgeOptions
+	if err := json.Unmarshal([]byte(submission.PendingRejudge), &opts); err != nil {
+		return err
+	}
+	submission.PendingRejudge = ""
+	_, err := s.requeueForRejudge(ctx, &submission, opts)
+	return err
+}
+
+// requeueForRejudge resets a judged submission and queues it again. It
+// returns false if the job could not be queued, the submission is then
+// marked as an error.
+func (s *JudgeService) requeueForRejudge(ctx context.Context, submission *database.Submission, opts RejudgeOptions) (bool, error) {
+	if err := s.resetForRejudge(submission, opts.Reason); err != nil {
+		return false, err
+	}
+
+	job, err := json.Marshal(&judgeJob{SubmissionID: submission.ID, RecomputeWinner: opts.RecomputeWinners})
+	if err != nil {
+		return false, err
+	}
+	if err := s.queue.Publish(ctx, job); err != nil {
+		s.failSubmission(submission, fmt.Errorf("rejudge: %w", err))
+		return false, nil
+	}
+	return true, nil
+}
+
+// resetForRejudge records the submission's outcome in its history and
+// clears it
+func (s *JudgeService) resetForRejudge(submission *database.Submission, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		history := &database.SubmissionHistory{
+			ID:           uuid.New(),
+			SubmissionID: submission.ID,
+			Status:       submission.Status,
+			Verdict:      submission.Verdict,
+			Score:        submission.Score,
+			Runtime:      submission.Runtime,
+			Memory:       submission.Memory,
+			ErrorMsg:     submission.ErrorMsg,
+			TestResults:  submission.TestResults,
+			Subtasks:     submission.Subtasks,
+			Reason:       reason,
+		}
+		if history.TestResults == "" {
+			history.TestResults = "[]"
+		}
+		if history.Subtasks == "" {
+			history.Subtasks = "[]"
+		}
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+
+		submission.Status = "pending"
+		submission.Verdict = ""
+		submission.Score = 0
+		submission.Runtime = 0
+		submission.Memory = 0
+		submission.ErrorMsg = ""
+		submission.TestResults = "[]"
+		submission.Subtasks = "[]"
+		submission.TestsDone = 0
+		return tx.Save(submission).Error
+	})
+}
+
+// GetSubmissionHistory returns the earlier outcomes of a submission, oldest first
+func (s *JudgeService) GetSubmissionHistory(submissionID uuid.UUID) ([]database.SubmissionHistory, error) {
+	var history []database.SubmissionHistory
+	err := s.db.Where("submission_id = ?", submissionID).Order("created_at ASC").Find(&history).Error
+	return history, err
+}
+
+// recomputeWinnerWhenJudged sets the match winner again once no
+// submission of the match is waiting to be judged
+func (s *JudgeService) recomputeWinnerWhenJudged(matchID uuid.UUID) {
+	var waiting int64
+	if err := s.db.Model(&database.Submission{}).
+		Where("match_id = ? AND status IN ?", matchID, []string{"pending", "running"}).
+		Count(&waiting).Error; err != nil {
+		log.Printf("Rejudge: match %s: %v", matchID, err)
+		return
+	}
+	if waiting > 0 {
+		return
+	}
+
+	changed, err := recomputeWinner(s.db, s.ratings, matchID)
+	if err != nil {
+		log.Printf("Rejudge: match %s: recompute winner: %v", matchID, err)
+	} else if changed {
+		log.Printf("Rejudge: match %s has a new winner", matchID)
+	}
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"coderoulette/api"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// JudgeClient calls a judge service running in cmd/judge over gRPC. Its
+// methods mirror the JudgeService ones and return the same errors.
+type JudgeClient struct {
+	conn   *grpc.ClientConn
+	client api.JudgeClient
+}
+
+// NewJudgeClient connects lazily to the judge service at addr
+func NewJudgeClient(addr string) (*JudgeClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &JudgeClient{conn: conn, client: api.NewJudgeClient(conn)}, nil
+}
+
+func (c *JudgeClient) Close() error {
+	return c.conn.Close()
+}
+
+// SubmitCode records a submission on the judge and queues it for judging
+func (c *JudgeClient) SubmitCode(ctx context.Context, matchID, playerID uuid.UUID, code, language, mode string) (*SubmissionData, error) {
+	msg, err := c.client.Submit(ctx, &api.SubmitRequest{
+		MatchId:  matchID.String(),
+		PlayerId: playerID.String(),
+		Code:     code,
+		Language: language,
+		Mode:     mode,
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return submissionFromProto(msg)
+}
+
+// GetSubmission returns a submission by ID, including its judging progress
+func (c *JudgeClient) GetSubmission(ctx context.Context, id uuid.UUID) (*SubmissionData, error) {
+	msg, err := c.client.GetResult(ctx, &api.GetResultRequest{SubmissionId: id.String()})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return submissionFromProto(msg)
+}
+
+// WatchSubmission calls fn with the submission whenever its status or
+// progress changes, until it is judged, fn fails or ctx is cancelled
+func (c *JudgeClient) WatchSubmission(ctx context.Context, id uuid.UUID, fn func(*SubmissionData) error) error {
+	stream, err := c.client.StreamProgress(ctx, &api.GetResultRequest{SubmissionId: id.String()})
+	if err != nil {
+		return rpcError(err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return rpcError(err)
+		}
+
+		submission, err := submissionFromProto(msg)
+		if err != nil {
+			return err
+		}
+		if err := fn(submission); err != nil {
+			return err
+		}
+	}
+}
+
+// RunCustomInput runs code once on custom input on the judge
+func (c *JudgeClient) RunCustomInput(ctx context.Context, matchID, playerID uuid.UUID, code, language, input string) (*RunResult, error) {
+	req := &api.RunRequest{Code: code, Language: language, Input: input}
+	if matchID != uuid.Nil {
+		req.MatchId = matchID.String()
+	}
+	if playerID != uuid.Nil {
+		req.PlayerId = playerID.String()
+	}
+
+	msg, err := c.client.Run(ctx, req)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return &RunResult{
+		Verdict:  Verdict(msg.Verdict),
+		Stdout:   msg.Stdout,
+		Stderr:   msg.Stderr,
+		ExitCode: int(msg.ExitCode),
+		Signal:   msg.Signal,
+		Runtime:  int(msg.Runtime),
+		WallTime: int(msg.WallTime),
+		Memory:   int(msg.Memory),
+	}, nil
+}
+
+// GenerateTests replaces the problem's generated tests on the judge
+func (c *JudgeClient) GenerateTests(ctx context.Context, problem *ProblemData) error {
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	msg, err := c.client.Generate(ctx, &api.ProblemRequest{Problem: data})
+	if err != nil {
+		return rpcError(err)
+	}
+	var generated ProblemData
+	if err := json.Unmarshal(msg.Problem, &generated); err != nil {
+		return err
+	}
+	problem.TestCases = generated.TestCases
+	return nil
+}
+
+// ValidateProblem runs the problem's solutions on the judge, problems
+// without a reference solution get no report
+func (c *JudgeClient) ValidateProblem(ctx context.Context, problem *ProblemData) (*ValidationReport, error) {
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.client.Validate(ctx, &api.ProblemRequest{Problem: data})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	if msg.Reference == nil {
+		return nil, nil
+	}
+
+	report := &ValidationReport{
+		Valid:       msg.Valid,
+		Reference:   solutionCheckFromProto(msg.Reference),
+		ValidatedAt: msg.ValidatedAt.AsTime(),
+	}
+	for _, check := range msg.WrongSolutions {
+		report.WrongSolutions = append(report.WrongSolutions, *solutionCheckFromProto(check))
+	}
+	return report, nil
+}
+
+// StressTest compares a solution with the problem's reference on the judge
+func (c *JudgeClient) StressTest(ctx context.Context, problem *ProblemData, code, language string, opts StressOptions) (*StressResult, error) {
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.client.Stress(ctx, &api.StressRequest{
+		Problem:   data,
+		Code:      code,
+		Language:  language,
+		Generator: opts.Generator,
+		Runs:      int32(opts.Runs),
+		Seed:      opts.Seed,
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	result := &StressResult{
+		Seed:     msg.Seed,
+		Runs:     int(msg.Runs),
+		Passed:   msg.Passed,
+		Verdict:  Verdict(msg.Verdict),
+		ErrorMsg: msg.ErrorMsg,
+	}
+	if counterexample := msg.Counterexample; counterexample != nil {
+		result.Counterexample = &Counterexample{
+			Generator: counterexample.Generator,
+			Seed:      counterexample.Seed,
+			Input:     counterexample.Input,
+			Expected:  counterexample.Expected,
+			Actual:    counterexample.Actual,
+			Verdict:   Verdict(counterexample.Verdict),
+			Message:   counterexample.Message,
+		}
+	}
+	return result, nil
+}
+
+// Languages returns the languages the judge accepts
+func (c *JudgeClient) Languages(ctx context.Context) ([]LanguageInfo, error) {
+	resp, err := c.client.ListLanguages(ctx, &api.ListLanguagesRequest{})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	languages := make([]LanguageInfo, 0, len(resp.Languages))
+	for _, language := range resp.Languages {
+		languages = append(languages, LanguageInfo{
+			Name:        language.Name,
+			DisplayName: language.DisplayName,
+			Version:     language.Version,
+			SourceFile:  language.SourceFile,
+			Compiled:    language.Compiled,
+		})
+	}
+	return languages, nil
+}
+
+// remoteError keeps the message of a judge error while matching its
+// sentinel with errors.Is
+type remoteError struct {
+	err error
+	msg string
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.err }
+
+// rpcError turns a gRPC status from the judge back into a judge error
+func rpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.Unavailable:
+		return &remoteError{err: ErrJudgeUnavailable, msg: ErrJudgeUnavailable.Error() + ": " + st.Message()}
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != rpcErrorDomain {
+			continue
+		}
+		for _, known := range rpcErrors {
+			if known.reason == info.Reason {
+				return &remoteError{err: known.err, msg: st.Message()}
+			}
+		}
+	}
+	return err
+}
+
+func submissionFromProto(msg *api.Submission) (*SubmissionData, error) {
+	id, err := uuid.Parse(msg.Id)
+	if err != nil {
+		return nil, err
+	}
+	matchID, err := uuid.Parse(msg.MatchId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := uuid.Parse(msg.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := &SubmissionData{
+		ID:          id,
+		MatchID:     matchID,
+		PlayerID:    playerID,
+		Code:        msg.Code,
+		Language:    msg.Language,
+		Mode:        msg.Mode,
+		Status:      msg.Status,
+		Verdict:     Verdict(msg.Verdict),
+		Score:       int(msg.Score),
+		Runtime:     int(msg.Runtime),
+		Memory:      int(msg.Memory),
+		ErrorMsg:    msg.ErrorMsg,
+		TestResults: []TestCaseResult{},
+		Progress:    Progress{Done: int(msg.TestsDone), Total: int(msg.TestsTotal)},
+		CreatedAt:   msg.CreatedAt.AsTime(),
+	}
+	for _, test := range msg.TestResults {
+		submission.TestResults = append(submission.TestResults, TestCaseResult{
+			Input:    test.Input,
+			Expected: test.Expected,
+			Actual:   test.Actual,
+			Passed:   test.Passed,
+			Runtime:  int(test.Runtime),
+			Memory:   int(test.Memory),
+			Verdict:  Verdict(test.Verdict),
+			ExitCode: int(test.ExitCode),
+			Signal:   test.Signal,
+			Hidden:   test.Hidden,
+			Group:    test.Group,
+			Score:    int(test.Score),
+			Message:  test.Message,
+		})
+	}
+	for _, subtask := range msg.Subtasks {
+		submission.Subtasks = append(submission.Subtasks, SubtaskResult{
+			Name:    subtask.Name,
+			Points:  int(subtask.Points),
+			Score:   int(subtask.Score),
+			Passed:  int(subtask.Passed),
+			Total:   int(subtask.Total),
+			Verdict: Verdict(subtask.Verdict),
+		})
+	}
+	if analysis := msg.Analysis; analysis != nil {
+		submission.Analysis = &Analysis{Findings: []Finding{}}
+		submission.Analysis.Lines = int(analysis.Lines)
+		submission.Analysis.Complexity = int(analysis.Complexity)
+		for _, finding := range analysis.Findings {
+			submission.Analysis.Findings = append(submission.Analysis.Findings, Finding{
+				Tool:    finding.Tool,
+				Line:    int(finding.Line),
+				Column:  int(finding.Column),
+				Message: finding.Message,
+				Detail:  finding.Detail,
+			})
+		}
+	}
+	return submission, nil
+}
+
+func solutionCheckFromProto(msg *api.SolutionCheck) *SolutionCheck {
+	return &SolutionCheck{
+		Name:     msg.Name,
+		Language: msg.Language,
+		Verdict:  Verdict(msg.Verdict),
+		Score:    int(msg.Score),
+		Runtime:  int(msg.Runtime),
+		Memory:   int(msg.Memory),
+		ErrorMsg: msg.ErrorMsg,
+		OK:       msg.Ok,
+	}
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"coderoulette/api"
+	"coderoulette/internal/mq"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// rpcErrorDomain scopes the reasons of judge errors sent over gRPC
+const rpcErrorDomain = "judge.coderoulette"
+
+// rpcErrors are the judge errors callers tell apart, with the gRPC code and
+// reason they travel as
+var rpcErrors = []struct {
+	err    error
+	code   codes.Code
+	reason string
+}{
+	{ErrMatchNotFound, codes.NotFound, "MATCH_NOT_FOUND"},
+	{ErrSubmissionNotFound, codes.NotFound, "SUBMISSION_NOT_FOUND"},
+	{ErrNotInMatch, codes.FailedPrecondition, "NOT_IN_MATCH"},
+	{ErrNoProblem, codes.FailedPrecondition, "NO_PROBLEM"},
+	{ErrMatchNotActive, codes.FailedPrecondition, "MATCH_NOT_ACTIVE"},
+	{ErrUnsupportedLanguage, codes.InvalidArgument, "UNSUPPORTED_LANGUAGE"},
+	{ErrInvalidInput, codes.InvalidArgument, "INVALID_INPUT"},
+	{mq.ErrQueueFull, codes.ResourceExhausted, "QUEUE_FULL"},
+}
+
+// JudgeServer serves a JudgeService over gRPC, see api/judge.proto
+type JudgeServer struct {
+	api.UnimplementedJudgeServer
+	judge *JudgeService
+}
+
+func NewJudgeServer(judge *JudgeService) *JudgeServer {
+	return &JudgeServer{judge: judge}
+}
+
+// Submit stores a submission and queues it for judging
+func (s *JudgeServer) Submit(ctx context.Context, req *api.SubmitRequest) (*api.Submission, error) {
+	matchID, err := uuid.Parse(req.MatchId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid match ID")
+	}
+	playerID, err := uuid.Parse(req.PlayerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid player ID")
+	}
+
+	submission, err := s.judge.SubmitCode(ctx, matchID, playerID, req.Code, req.Language, req.Mode)
+	if err != nil {
+		return nil, rpcStatus(err)
+	}
+	return submissionToProto(submission), nil
+}
+
+// GetResult returns a submission with its progress and verdict
+func (s *JudgeServer) GetResult(ctx context.Context, req *api.GetResultRequest) (*api.Submission, error) {
+	id, err := uuid.Parse(req.SubmissionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid submission ID")
+	}
+
+	submission, err := s.judge.GetSubmission(id)
+	if err != nil {
+		return nil, rpcStatus(err)
+	}
+	return submissionToProto(submission), nil
+}
+
+// StreamProgress sends the submission whenever it changes until it is judged
+func (s *JudgeServer) StreamProgress(req *api.GetResultRequest, stream grpc.ServerStreamingServer[api.Submission]) error {
+	id, err := uuid.Parse(req.SubmissionId)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid submission ID")
+	}
+
+	err = s.judge.WatchSubmission(stream.Context(), id, func(submission *SubmissionData) error {
+		return stream.Send(submissionToProto(submission))
+	})
+	if err != nil {
+		return rpcStatus(err)
+	}
+	return nil
+}
+
+// ListLanguages returns the languages the judge accepts
+func (s *JudgeServer) ListLanguages(ctx context.Context, req *api.ListLanguagesRequest) (*api.ListLanguagesResponse, error) {
+	resp := &api.ListLanguagesResponse{}
+	for _, language := range s.judge.Languages().List() {
+		resp.Languages = append(resp.Languages, &api.Language{
+			Name:        language.Name,
+			DisplayName: language.DisplayName,
+			Version:     language.Version,
+			SourceFile:  language.SourceFile,
+			Compiled:    language.Compiled,
+		})
+	}
+	return resp, nil
+}
+
+// Run runs code once on custom input
+func (s *JudgeServer) Run(ctx context.Context, req *api.RunRequest) (*api.RunResult, error) {
+	var matchID, playerID uuid.UUID
+	if req.MatchId != "" {
+		id, err := uuid.Parse(req.MatchId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid match ID")
+		}
+		matchID = id
+	}
+	if req.PlayerId != "" {
+		id, err := uuid.Parse(req.PlayerId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid player ID")
+		}
+		playerID = id
+	}
+
+	result, err := s.judge.RunCustomInput(ctx, matchID, playerID, req.Code, req.Language, req.Input)
+	if err != nil {
+		return nil, rpcStatus(err)
+	}
+	return &api.RunResult{
+		Verdict:  string(result.Verdict),
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		ExitCode: int32(result.ExitCode),
+		Signal:   result.Signal,
+		Runtime:  int32(result.Runtime),
+		WallTime: int32(result.WallTime),
+		Memory:   int32(result.Memory),
+	}, nil
+}
+
+// Generate replaces the problem's generated tests
+func (s *JudgeServer) Generate(ctx context.Context, req *api.ProblemRequest) (*api.ProblemRequest, error) {
+	problem, err := problemFromProto(req.Problem)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.judge.GenerateTests(ctx, problem); err != nil {
+		return nil, rpcStatus(err)
+	}
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &api.ProblemRequest{Problem: data}, nil
+}
+
+// Validate runs the problem's solutions, a problem without a reference
+// solution gets an empty report
+func (s *JudgeServer) Validate(ctx context.Context, req *api.ProblemRequest) (*api.ValidationReport, error) {
+	problem, err := problemFromProto(req.Problem)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.judge.ValidateProblem(ctx, problem)
+	if err != nil {
+		return nil, rpcStatus(err)
+	}
+	if report == nil {
+		return &api.ValidationReport{}, nil
+	}
+
+	msg := &api.ValidationReport{
+		Valid:       report.Valid,
+		Reference:   solutionCheckToProto(report.Reference),
+		ValidatedAt: timestamppb.New(report.ValidatedAt),
+	}
+	for i := range report.WrongSolutions {
+		msg.WrongSolutions = append(msg.WrongSolutions, solutionCheckToProto(&report.WrongSolutions[i]))
+	}
+	return msg, nil
+}
+
+// Stress compares a solution with the problem's reference
+func (s *JudgeServer) Stress(ctx context.Context, req *api.StressRequest) (*api.StressResult, error) {
+	problem, err := problemFromProto(req.Problem)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.judge.StressTest(ctx, problem, req.Code, req.Language, StressOptions{
+		Generator: req.Generator,
+		Runs:      int(req.Runs),
+		Seed:      req.Seed,
+	})
+	if err != nil {
+		return nil, rpcStatus(err)
+	}
+
+	msg := &api.StressResult{
+		Seed:     result.Seed,
+		Runs:     int32(result.Runs),
+		Passed:   result.Passed,
+		Verdict:  string(result.Verdict),
+		ErrorMsg: result.ErrorMsg,
+	}
+	if counterexample := result.Counterexample; counterexample != nil {
+		msg.Counterexample = &api.Counterexample{
+			Generator: counterexample.Generator,
+			Seed:      counterexample.Seed,
+			Input:     counterexample.Input,
+			Expected:  counterexample.Expected,
+			Actual:    counterexample.Actual,
+			Verdict:   string(counterexample.Verdict),
+			Message:   counterexample.Message,
+		}
+	}
+	return msg, nil
+}
+
+func problemFromProto(data []byte) (*ProblemData, error) {
+	var problem ProblemData
+	if err := json.Unmarshal(data, &problem); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid problem: "+err.Error())
+	}
+	return &problem, nil
+}
+
+func solutionCheckToProto(check *SolutionCheck) *api.SolutionCheck {
+	return &api.SolutionCheck{
+		Name:     check.Name,
+		Language: check.Language,
+		Verdict:  string(check.Verdict),
+		Score:    int32(check.Score),
+		Runtime:  int32(check.Runtime),
+		Memory:   int32(check.Memory),
+		ErrorMsg: check.ErrorMsg,
+		Ok:       check.OK,
+	}
+}
+
+// rpcStatus converts a judge error to a gRPC status carrying its reason
+func rpcStatus(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = ErrSubmissionNotFound
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	for _, known := range rpcErrors {
+		if !errors.Is(err, known.err) {
+			continue
+		}
+		st, detailErr := status.New(known.code, err.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason: known.reason,
+			Domain: rpcErrorDomain,
+		})
+		if detailErr != nil {
+			return status.Error(known.code, err.Error())
+		}
+		return st.Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func submissionToProto(submission *SubmissionData) *api.Submission {
+	msg := &api.Submission{
+		Id:         submission.ID.String(),
+		MatchId:    submission.MatchID.String(),
+		PlayerId:   submission.PlayerID.String(),
+		Code:       submission.Code,
+		Language:   submission.Language,
+		Mode:       submission.Mode,
+		Status:     submission.Status,
+		Verdict:    string(submission.Verdict),
+		Score:      int32(submission.Score),
+		Runtime:    int32(submission.Runtime),
+		Memory:     int32(submission.Memory),
+		ErrorMsg:   submission.ErrorMsg,
+		TestsDone:  int32(submission.Progress.Done),
+		TestsTotal: int32(submission.Progress.Total),
+		CreatedAt:  timestamppb.New(submission.CreatedAt),
+	}
+	for _, test := range submission.TestResults {
+		msg.TestResults = append(msg.TestResults, &api.TestCaseResult{
+			Input:    test.Input,
+			Expected: test.Expected,
+			Actual:   test.Actual,
+			Passed:   test.Passed,
+			Runtime:  int32(test.Runtime),
+			Memory:   int32(test.Memory),
+			Verdict:  string(test.Verdict),
+			ExitCode: int32(test.ExitCode),
+			Signal:   test.Signal,
+			Hidden:   test.Hidden,
+			Group:    test.Group,
+			Score:    int32(test.Score),
+			Message:  test.Message,
+		})
+	}
+	for _, subtask := range submission.Subtasks {
+		msg.Subtasks = append(msg.Subtasks, &api.SubtaskResult{
+			Name:    subtask.Name,
+			Points:  int32(subtask.Points),
+			Score:   int32(subtask.Score),
+			Passed:  int32(subtask.Passed),
+			Total:   int32(subtask.Total),
+			Verdict: string(subtask.Verdict),
+		})
+	}
+	if analysis := submission.Analysis; analysis != nil {
+		msg.Analysis = &api.Analysis{Lines: int32(analysis.Lines), Complexity: int32(analysis.Complexity)}
+		for _, finding := range analysis.Findings {
+			msg.Analysis.Findings = append(msg.Analysis.Findings, &api.Finding{
+				Tool:    finding.Tool,
+				Line:    int32(finding.Line),
+				Column:  int32(finding.Column),
+				Message: finding.Message,
+				Detail:  finding.Detail,
+			})
+		}
+	}
+	return msg
+}
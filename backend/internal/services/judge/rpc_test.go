@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"coderoulette/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// rpcJudge serves a judge without a sandbox in memory and returns a
+// client of it
+func rpcJudge(t *testing.T) *JudgeClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	api.RegisterJudgeServer(server, NewJudgeServer(&JudgeService{}))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///judge",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &JudgeClient{conn: conn, client: api.NewJudgeClient(conn)}
+}
+
+func TestJudgeClientProblemChecks(t *testing.T) {
+	ctx := context.Background()
+	client := rpcJudge(t)
+
+	// Neither needs to run code for a problem without seeds or reference
+	problem := &ProblemData{
+		Language: "go",
+		TestCases: []TestCase{
+			{Input: "1", Output: "1", Hidden: true, Group: "small"},
+			{Input: "2", Output: "2", Generated: "gen#old"},
+		},
+		Generators: []Generator{{Name: "gen", Language: "python"}},
+	}
+	if err := client.GenerateTests(ctx, problem); err != nil {
+		t.Fatalf("GenerateTests: %v", err)
+	}
+	if len(problem.TestCases) != 1 || problem.TestCases[0] != (TestCase{Input: "1", Output: "1", Hidden: true, Group: "small"}) {
+		t.Errorf("test cases = %+v, want only the hand-written one", problem.TestCases)
+	}
+
+	report, err := client.ValidateProblem(ctx, problem)
+	if err != nil || report != nil {
+		t.Errorf("ValidateProblem = %+v, %v, want no report", report, err)
+	}
+}
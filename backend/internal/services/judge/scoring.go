@@ -0,0 +1,73 @@
+package services
+
+// SubtaskResult is the outcome of one subtask of a submission
+type SubtaskResult struct {
+	Name    string  `json:"name"`
+	Points  int     `json:"points"` // maximum
+	Score   int     `json:"score"`  // points earned
+	Passed  int     `json:"passed"` // tests passed
+	Total   int     `json:"total"`
+	Verdict Verdict `json:"verdict"` // of the first failing test, AC if all pass
+}
+
+// scoreSubtasks scores each subtask from the test results and returns the
+// breakdown with the overall score out of 100
+func scoreSubtasks(subtasks []Subtask, testCases []TestCase, results []TestCaseResult) ([]SubtaskResult, int) {
+	breakdown := make([]SubtaskResult, 0, len(subtasks))
+	earned, total := 0, 0
+
+	for _, subtask := range subtasks {
+		result := SubtaskResult{Name: subtask.Name, Points: subtask.Points, Verdict: VerdictAccepted}
+		minScore := 100
+		weightedScore, totalWeight := 0, 0
+
+		for i, testCase := range testCases {
+			if testCase.Group != subtask.Name || i >= len(results) {
+				continue
+			}
+			test := results[i]
+
+			result.Total++
+			if test.Passed {
+				result.Passed++
+			} else if result.Verdict == VerdictAccepted {
+				result.Verdict = test.Verdict
+			}
+			if test.Score < minScore {
+				minScore = test.Score
+			}
+			weight := testCase.Weight
+			if weight <= 0 {
+				weight = 1
+			}
+			weightedScore += weight * test.Score
+			totalWeight += weight
+		}
+
+		switch subtask.Policy {
+		case SubtaskMin:
+			result.Score = subtask.Points * minScore / 100
+		case SubtaskSum:
+			if totalWeight > 0 {
+				result.Score = subtask.Points * weightedScore / (100 * totalWeight)
+			}
+		default:
+			if result.Total > 0 && result.Passed == result.Total {
+				result.Score = subtask.Points
+			}
+		}
+		// A subtask without tests earns nothing
+		if result.Total == 0 {
+			result.Score = 0
+		}
+
+		earned += result.Score
+		total += subtask.Points
+		breakdown = append(breakdown, result)
+	}
+
+	if total == 0 {
+		return breakdown, 0
+	}
+	return breakdown, earned * 100 / total
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScoreSubtasks(t *testing.T) {
+	ac := TestCaseResult{Passed: true, Verdict: VerdictAccepted, Score: 100}
+	wa := TestCaseResult{Verdict: VerdictWrongAnswer}
+	tle := TestCaseResult{Verdict: VerdictTimeLimitExceeded}
+	partial := func(score int) TestCaseResult {
+		return TestCaseResult{Verdict: VerdictWrongAnswer, Score: score}
+	}
+	group := func(name string, weight int) TestCase {
+		return TestCase{Group: name, Weight: weight}
+	}
+
+	tests := []struct {
+		name      string
+		subtasks  []Subtask
+		testCases []TestCase
+		results   []TestCaseResult
+		want      []SubtaskResult
+		score     int
+	}{
+		{
+			"all passed",
+			[]Subtask{{Name: "a", Points: 40}},
+			[]TestCase{group("a", 0), group("a", 0)},
+			[]TestCaseResult{ac, ac},
+			[]SubtaskResult{{Name: "a", Points: 40, Score: 40, Passed: 2, Total: 2, Verdict: VerdictAccepted}},
+			100,
+		},
+		{
+			"all with a failure",
+			[]Subtask{{Name: "a", Points: 40, Policy: SubtaskAll}},
+			[]TestCase{group("a", 0), group("a", 0), group("a", 0)},
+			[]TestCaseResult{ac, tle, wa},
+			[]SubtaskResult{{Name: "a", Points: 40, Passed: 1, Total: 3, Verdict: VerdictTimeLimitExceeded}},
+			0,
+		},
+		{
+			"all ignores partial scores",
+			[]Subtask{{Name: "a", Points: 40}},
+			[]TestCase{group("a", 0), group("a", 0)},
+			[]TestCaseResult{ac, partial(90)},
+			[]SubtaskResult{{Name: "a", Points: 40, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer}},
+			0,
+		},
+		{
+			"min",
+			[]Subtask{{Name: "a", Points: 50, Policy: SubtaskMin}},
+			[]TestCase{group("a", 0), group("a", 0), group("a", 0)},
+			[]TestCaseResult{ac, partial(40), partial(70)},
+			[]SubtaskResult{{Name: "a", Points: 50, Score: 20, Passed: 1, Total: 3, Verdict: VerdictWrongAnswer}},
+			40,
+		},
+		{
+			"min ignores weights",
+			[]Subtask{{Name: "a", Points: 50, Policy: SubtaskMin}},
+			[]TestCase{group("a", 10), group("a", 0)},
+			[]TestCaseResult{ac, partial(50)},
+			[]SubtaskResult{{Name: "a", Points: 50, Score: 25, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer}},
+			50,
+		},
+		{
+			"sum weighted",
+			[]Subtask{{Name: "a", Points: 30, Policy: SubtaskSum}},
+			[]TestCase{group("a", 1), group("a", 2)},
+			[]TestCaseResult{ac, partial(40)},
+			[]SubtaskResult{{Name: "a", Points: 30, Score: 18, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer}},
+			60,
+		},
+		{
+			"sum with zero weights counted as one",
+			[]Subtask{{Name: "a", Points: 10, Policy: SubtaskSum}},
+			[]TestCase{group("a", 0), group("a", 0)},
+			[]TestCaseResult{ac, wa},
+			[]SubtaskResult{{Name: "a", Points: 10, Score: 5, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer}},
+			50,
+		},
+		{
+			"sum rounds down",
+			[]Subtask{{Name: "a", Points: 10, Policy: SubtaskSum}},
+			[]TestCase{group("a", 0), group("a", 3)},
+			[]TestCaseResult{wa, ac},
+			[]SubtaskResult{{Name: "a", Points: 10, Score: 7, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer}},
+			70,
+		},
+		{
+			"groups without tests earn nothing",
+			[]Subtask{
+				{Name: "a", Points: 60},
+				{Name: "b", Points: 20, Policy: SubtaskMin},
+				{Name: "c", Points: 10, Policy: SubtaskSum},
+				{Name: "d", Points: 10, Policy: SubtaskAll},
+			},
+			[]TestCase{group("a", 0)},
+			[]TestCaseResult{ac},
+			[]SubtaskResult{
+				{Name: "a", Points: 60, Score: 60, Passed: 1, Total: 1, Verdict: VerdictAccepted},
+				{Name: "b", Points: 20, Verdict: VerdictAccepted},
+				{Name: "c", Points: 10, Verdict: VerdictAccepted},
+				{Name: "d", Points: 10, Verdict: VerdictAccepted},
+			},
+			60,
+		},
+		{
+			"tests of other groups ignored",
+			[]Subtask{{Name: "a", Points: 30}, {Name: "b", Points: 70, Policy: SubtaskSum}},
+			[]TestCase{group("b", 0), group("a", 0), group("", 0), group("b", 0), group("x", 0)},
+			[]TestCaseResult{ac, ac, wa, partial(50), wa},
+			[]SubtaskResult{
+				{Name: "a", Points: 30, Score: 30, Passed: 1, Total: 1, Verdict: VerdictAccepted},
+				{Name: "b", Points: 70, Score: 52, Passed: 1, Total: 2, Verdict: VerdictWrongAnswer},
+			},
+			82,
+		},
+		{
+			"no subtasks",
+			nil,
+			[]TestCase{group("a", 0)},
+			[]TestCaseResult{ac},
+			[]SubtaskResult{},
+			0,
+		},
+		{
+			"no points",
+			[]Subtask{{Name: "a"}},
+			[]TestCase{group("a", 0)},
+			[]TestCaseResult{ac},
+			[]SubtaskResult{{Name: "a", Passed: 1, Total: 1, Verdict: VerdictAccepted}},
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, score := scoreSubtasks(tt.subtasks, tt.testCases, tt.results)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scoreSubtasks breakdown = %+v, want %+v", got, tt.want)
+			}
+			if score != tt.score {
+				t.Errorf("scoreSubtasks score = %d, want %d", score, tt.score)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ValidationReport is the outcome of running a problem's solutions
+type ValidationReport struct {
+	Valid          bool            `json:"valid"`
+	Reference      *SolutionCheck  `json:"reference"`
+	WrongSolutions []SolutionCheck `json:"wrong_solutions,omitempty"`
+	ValidatedAt    time.Time       `json:"validated_at"`
+}
+
+// SolutionCheck is how one solution fared against the problem's tests
+type SolutionCheck struct {
+	Name     string  `json:"name"`
+	Language string  `json:"language"`
+	Verdict  Verdict `json:"verdict"`
+	Score    int     `json:"score"`
+	Runtime  int     `json:"runtime"` // CPU time of all tests in milliseconds
+	Memory   int     `json:"memory"`  // peak memory of any test in kilobytes
+	ErrorMsg string  `json:"error_msg,omitempty"`
+	OK       bool    `json:"ok"` // behaved as expected
+}
+
+// ValidateProblem runs the problem's reference solution, which has to pass
+// every test within the limits, and its known-wrong solutions, which have
+// to compile and fail a test each. Problems without a reference solution
+// get no report.
+func (s *JudgeService) ValidateProblem(ctx context.Context, problem *ProblemData) (*ValidationReport, error) {
+	if problem.Solution == "" {
+		return nil, nil
+	}
+
+	reference, err := s.checkSolution(ctx, problem, Solution{
+		Name:     "reference",
+		Code:     problem.Solution,
+		Language: problem.Language,
+	}, ModeRunAll)
+	if err != nil {
+		return nil, fmt.Errorf("reference solution: %w", err)
+	}
+	reference.OK = reference.Verdict == VerdictAccepted
+
+	report := &ValidationReport{Valid: reference.OK, Reference: reference, ValidatedAt: time.Now()}
+	for i, solution := range problem.WrongSolutions {
+		if solution.Name == "" {
+			solution.Name = fmt.Sprintf("wrong solution %d", i+1)
+		}
+		// One failing test is enough to show the solution is caught
+		check, err := s.checkSolution(ctx, problem, solution, ModeStopOnFailure)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", solution.Name, err)
+		}
+		check.OK = check.Verdict != VerdictAccepted && check.Verdict != VerdictCompilationError
+		report.Valid = report.Valid && check.OK
+		report.WrongSolutions = append(report.WrongSolutions, *check)
+	}
+	return report, nil
+}
+
+// checkSolution judges a solution against all of the problem's tests
+func (s *JudgeService) checkSolution(ctx context.Context, problem *ProblemData, solution Solution, mode string) (*SolutionCheck, error) {
+	result, err := s.judgeCode(ctx, solution.Code, solution.Language, problem, mode, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SolutionCheck{
+		Name:     solution.Name,
+		Language: solution.Language,
+		Verdict:  result.Verdict,
+		Score:    result.Score,
+		Runtime:  result.Runtime,
+		Memory:   result.Memory,
+		ErrorMsg: result.ErrorMsg,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"coderoulette/internal/sandbox"
+)
+
+// Judging modes of a submission
+const (
+	ModeRunAll        = "run_all"         // run every test, for full partial scores
+	ModeStopOnFailure = "stop_on_failure" // skip the tests after the first failure, for fast feedback
+)
+
+// ValidMode reports whether mode is a known judging mode
+func ValidMode(mode string) bool {
+	return mode == ModeRunAll || mode == ModeStopOnFailure
+}
+
+// testOutcome is a finished test case
+type testOutcome struct {
+	result *TestCaseResult
+	run    *sandbox.Result // the player's program, nil for skipped tests
+}
+
+// runTests runs the test cases with up to parallelism at a time. Outcomes
+// and progress are always in test order. In stop-on-failure mode no test
+// after the first failing one is started, tests after it that are still
+// running are cancelled, and all of them are reported as skipped.
+func (j *testJudge) runTests(ctx context.Context, mode string, parallelism int, progress ProgressFunc) ([]testOutcome, error) {
+	testCases := j.problem.TestCases
+	n := len(testCases)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	outcomes := make([]testOutcome, n)
+	finished := make([]bool, n)
+	cancels := make(map[int]context.CancelFunc)
+	var firstErr error
+	errIndex := n
+	firstFailure := n // lowest failing index so far
+	next := 0         // next test to start
+	reported := 0     // tests passed to progress so far
+
+	var mu sync.Mutex
+	stopped := func(i int) bool {
+		return mode == ModeStopOnFailure && i > firstFailure
+	}
+	// report passes the finished prefix of the tests to progress in order
+	report := func() {
+		for reported < n && (finished[reported] || stopped(reported)) {
+			if stopped(reported) {
+				outcomes[reported] = skippedOutcome(testCases[reported])
+			}
+			if progress != nil {
+				progress(reported+1, *outcomes[reported].result)
+			}
+			reported++
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				if i >= n || stopped(i) || firstErr != nil {
+					mu.Unlock()
+					return
+				}
+				next++
+				testCtx, cancel := context.WithCancel(ctx)
+				cancels[i] = cancel
+				mu.Unlock()
+
+				result, run, err := j.runTest(testCtx, i, testCases[i])
+
+				mu.Lock()
+				cancel()
+				delete(cancels, i)
+				if stopped(i) {
+					// Cancelled or no longer needed, reported as skipped
+					mu.Unlock()
+					continue
+				}
+				if err != nil {
+					if i < errIndex {
+						firstErr, errIndex = fmt.Errorf("test %d: %w", i+1, err), i
+					}
+					mu.Unlock()
+					return
+				}
+
+				outcomes[i] = testOutcome{result: result, run: run}
+				finished[i] = true
+				if !result.Passed && i < firstFailure {
+					firstFailure = i
+					if mode == ModeStopOnFailure {
+						for index, cancelTest := range cancels {
+							if index > i {
+								cancelTest()
+							}
+						}
+					}
+				}
+				report()
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	report()
+	mu.Unlock()
+	return outcomes, nil
+}
+
+func skippedOutcome(testCase TestCase) testOutcome {
+	return testOutcome{result: &TestCaseResult{
+		Input:    testCase.Input,
+		Expected: testCase.Output,
+		Verdict:  VerdictSkipped,
+		Hidden:   testCase.Hidden,
+		Group:    testCase.Group,
+	}}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"syscall"
+
+	"coderoulette/internal/sandbox"
+)
+
+// Verdict is the outcome of judging a single test or a whole submission
+type Verdict string
+
+const (
+	VerdictAccepted            Verdict = "AC"
+	VerdictWrongAnswer         Verdict = "WA"
+	VerdictTimeLimitExceeded   Verdict = "TLE"
+	VerdictMemoryLimitExceeded Verdict = "MLE"
+	VerdictRuntimeError        Verdict = "RE"
+	VerdictOutputLimitExceeded Verdict = "OLE"
+	VerdictCompilationError    Verdict = "CE"
+	VerdictSkipped             Verdict = "SK" // not run after an earlier test failed
+)
+
+// runVerdict classifies how a test run ended. Limit violations take
+// precedence over the exit status because the sandbox kills the process
+// when a limit is hit. AC only means the program ran cleanly, its output
+// still has to pass the problem's checker.
+func runVerdict(result *sandbox.Result) Verdict {
+	switch {
+	case result.MemoryLimitExceeded:
+		return VerdictMemoryLimitExceeded
+	case result.TimeLimitExceeded:
+		return VerdictTimeLimitExceeded
+	case result.OutputLimitExceeded:
+		return VerdictOutputLimitExceeded
+	case result.Signal != 0 || result.ExitCode != 0:
+		return VerdictRuntimeError
+	default:
+		return VerdictAccepted
+	}
+}
+
+// verdictMessage describes why a test failed for JudgeResult.ErrorMsg.
+// Stderr of hidden tests is left out since it could echo the hidden input.
+func verdictMessage(index int, verdict Verdict, result *sandbox.Result, hidden bool) string {
+	stderr := result.Stderr
+	if hidden {
+		stderr = nil
+	}
+
+	switch verdict {
+	case VerdictTimeLimitExceeded:
+		return fmt.Sprintf("Time limit exceeded on test %d", index+1)
+	case VerdictMemoryLimitExceeded:
+		return fmt.Sprintf("Memory limit exceeded on test %d", index+1)
+	case VerdictOutputLimitExceeded:
+		return fmt.Sprintf("Output limit exceeded on test %d", index+1)
+	case VerdictRuntimeError:
+		if result.Signal != 0 {
+			return fmt.Sprintf("Runtime error on test %d: killed by %s\n%s", index+1, signalName(result.Signal), stderr)
+		}
+		return fmt.Sprintf("Runtime error on test %d: exit code %d\n%s", index+1, result.ExitCode, stderr)
+	default:
+		return fmt.Sprintf("Wrong answer on test %d", index+1)
+	}
+}
+
+// compilationError builds the result for a submission that failed to compile
+func compilationError(result *sandbox.Result) *JudgeResult {
+	return &JudgeResult{
+		Verdict:  VerdictCompilationError,
+		Score:    0,
+		ErrorMsg: fmt.Sprintf("Compilation error: %s%s", result.Stdout, result.Stderr),
+	}
+}
+
+// signalName returns the conventional name of a signal, e.g. SIGSEGV
+func signalName(signal syscall.Signal) string {
+	if signal == 0 {
+		return ""
+	}
+	switch signal {
+	case syscall.SIGSEGV:
+		return "SIGSEGV"
+	case syscall.SIGABRT:
+		return "SIGABRT"
+	case syscall.SIGFPE:
+		return "SIGFPE"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	case syscall.SIGBUS:
+		return "SIGBUS"
+	case syscall.SIGXCPU:
+		return "SIGXCPU"
+	case syscall.SIGXFSZ:
+		return "SIGXFSZ"
+	default:
+		return fmt.Sprintf("signal %d", int(signal))
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+
+	"coderoulette/internal/sandbox"
+)
+
+func TestRunVerdict(t *testing.T) {
+	tests := []struct {
+		name   string
+		result sandbox.Result
+		want   Verdict
+	}{
+		{"clean exit", sandbox.Result{}, VerdictAccepted},
+		{"exit code", sandbox.Result{ExitCode: 1}, VerdictRuntimeError},
+		{"signal", sandbox.Result{Signal: syscall.SIGSEGV}, VerdictRuntimeError},
+		{"time limit", sandbox.Result{TimeLimitExceeded: true}, VerdictTimeLimitExceeded},
+		{"killed at time limit", sandbox.Result{TimeLimitExceeded: true, Signal: syscall.SIGXCPU}, VerdictTimeLimitExceeded},
+		{"memory limit", sandbox.Result{MemoryLimitExceeded: true, Signal: syscall.SIGKILL}, VerdictMemoryLimitExceeded},
+		{"memory before time", sandbox.Result{MemoryLimitExceeded: true, TimeLimitExceeded: true}, VerdictMemoryLimitExceeded},
+		{"output limit", sandbox.Result{OutputLimitExceeded: true, Signal: syscall.SIGPIPE}, VerdictOutputLimitExceeded},
+		{"time before output", sandbox.Result{TimeLimitExceeded: true, OutputLimitExceeded: true}, VerdictTimeLimitExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runVerdict(&tt.result); got != tt.want {
+				t.Errorf("runVerdict = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerdictMessage(t *testing.T) {
+	crashed := &sandbox.Result{Signal: syscall.SIGSEGV, Stderr: []byte("secret input")}
+	exited := &sandbox.Result{ExitCode: 3, Stderr: []byte("panic")}
+
+	tests := []struct {
+		name    string
+		verdict Verdict
+		result  *sandbox.Result
+		hidden  bool
+		want    string
+	}{
+		{"wrong answer", VerdictWrongAnswer, &sandbox.Result{}, false, "Wrong answer on test 2"},
+		{"time limit", VerdictTimeLimitExceeded, &sandbox.Result{}, false, "Time limit exceeded on test 2"},
+		{"memory limit", VerdictMemoryLimitExceeded, &sandbox.Result{}, false, "Memory limit exceeded on test 2"},
+		{"output limit", VerdictOutputLimitExceeded, &sandbox.Result{}, false, "Output limit exceeded on test 2"},
+		{"signal", VerdictRuntimeError, crashed, false, "Runtime error on test 2: killed by SIGSEGV\nsecret input"},
+		{"exit code", VerdictRuntimeError, exited, false, "Runtime error on test 2: exit code 3\npanic"},
+		{"hidden stderr", VerdictRuntimeError, crashed, true, "Runtime error on test 2: killed by SIGSEGV\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verdictMessage(1, tt.verdict, tt.result, tt.hidden); got != tt.want {
+				t.Errorf("verdictMessage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignalName(t *testing.T) {
+	tests := map[syscall.Signal]string{
+		0:               "",
+		syscall.SIGSEGV: "SIGSEGV",
+		syscall.SIGKILL: "SIGKILL",
+		syscall.SIGXCPU: "SIGXCPU",
+		syscall.SIGHUP:  "signal 1",
+	}
+	for signal, want := range tests {
+		if got := signalName(signal); got != want {
+			t.Errorf("signalName(%d) = %q, want %q", int(signal), got, want)
+		}
+	}
+}
+
+func TestCompilationError(t *testing.T) {
+	result := compilationError(&sandbox.Result{Stdout: []byte("main.go:1: "), Stderr: []byte("syntax error")})
+	if result.Verdict != VerdictCompilationError || result.Score != 0 {
+		t.Errorf("compilationError = %s with score %d, want CE with 0", result.Verdict, result.Score)
+	}
+	if !strings.HasSuffix(result.ErrorMsg, "main.go:1: syntax error") {
+		t.Errorf("compilationError message = %q, want the compiler output", result.ErrorMsg)
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"coderoulette/internal/database"
+	"coderoulette/internal/mq"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// judgeQueueStream is the Redis stream submissions are queued on
+const judgeQueueStream = "judge:submissions"
+
+// judgeJobTimeout bounds how long a job may stay unacknowledged before
+// another worker assumes its worker died and takes it over
+const judgeJobTimeout = 5 * time.Minute
+
+// judgeMaxDeliveries is how often a job is handed out before it is given
+// up on, so a submission that crashes its workers does not loop forever
+const judgeMaxDeliveries = 3
+
+// judgeJob is the queued unit of work for a judge worker
+type judgeJob struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+
+	// Rejudges may set the match winner again once the match is judged
+	RecomputeWinner bool `json:"recompute_winner,omitempty"`
+}
+
+// NewJudgeQueue returns the submission queue shared by the API and judge workers
+func NewJudgeQueue(redis *redis.Client, maxPending int64) *mq.Queue {
+	return mq.NewQueue(redis, judgeQueueStream, maxPending, judgeJobTimeout)
+}
+
+// RunWorkers consumes queued submissions with a bounded pool of workers
+// and blocks until ctx is cancelled and in-flight jobs have finished.
+func (s *JudgeService) RunWorkers(ctx context.Context, workers int) error {
+	if err := s.queue.Init(ctx); err != nil {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		consumer := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runWorker(ctx, consumer)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// runWorker judges one submission at a time until ctx is cancelled
+func (s *JudgeService) runWorker(ctx context.Context, consumer string) {
+	for ctx.Err() == nil {
+		msg, err := s.queue.Consume(ctx, consumer, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Judge worker %s: consume error: %v", consumer, err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+
+		var job judgeJob
+		if err := json.Unmarshal(msg.Payload, &job); err != nil {
+			log.Printf("Judge worker %s: dropping malformed job %s: %v", consumer, msg.ID, err)
+			s.queue.Ack(ctx, msg.ID)
+			continue
+		}
+
+		if msg.Deliveries > judgeMaxDeliveries {
+			log.Printf("Judge worker %s: giving up on submission %s after %d deliveries", consumer, job.SubmissionID, msg.Deliveries-1)
+			s.abandonJob(&job, msg.Deliveries-1)
+			if err := s.rejudgeIfRequested(context.WithoutCancel(ctx), job.SubmissionID); err != nil {
+				log.Printf("Judge worker %s: rejudge submission %s: %v", consumer, job.SubmissionID, err)
+			}
+			s.queue.Ack(context.WithoutCancel(ctx), msg.ID)
+			continue
+		}
+
+		// Finish the current job even if shutdown was requested meanwhile
+		jobCtx := context.WithoutCancel(ctx)
+		stop := s.keepClaimed(jobCtx, consumer, msg.ID)
+		if err := s.processJob(jobCtx, &job); err != nil {
+			log.Printf("Judge worker %s: submission %s: %v", consumer, job.SubmissionID, err)
+		}
+		stop()
+		if err := s.rejudgeIfRequested(jobCtx, job.SubmissionID); err != nil {
+			log.Printf("Judge worker %s: rejudge submission %s: %v", consumer, job.SubmissionID, err)
+		}
+		s.queue.Ack(jobCtx, msg.ID)
+	}
+}
+
+// keepClaimed refreshes the claim on a message until the returned stop
+// function is called, so other workers do not take over a long job
+func (s *JudgeService) keepClaimed(ctx context.Context, consumer, id string) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.queue.RefreshInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.queue.Refresh(ctx, consumer, id); err != nil && ctx.Err() == nil {
+					log.Printf("Judge worker %s: refresh job %s: %v", consumer, id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// abandonJob marks a submission whose job kept failing as not judgeable
+func (s *JudgeService) abandonJob(job *judgeJob, attempts int64) {
+	err := s.db.Model(&database.Submission{}).Where("id = ?", job.SubmissionID).Updates(map[string]interface{}{
+		"status":    "error",
+		"error_msg": fmt.Sprintf("judging failed %d times, giving up", attempts),
+	}).Error
+	if err != nil {
+		log.Printf("Failed to mark submission %s as failed: %v", job.SubmissionID, err)
+	}
+	if job.RecomputeWinner {
+		var submission database.Submission
+		if err := s.db.Select("match_id").First(&submission, "id = ?", job.SubmissionID).Error; err == nil {
+			s.recomputeWinnerWhenJudged(submission.MatchID)
+		}
+	}
+}
+
+// processJob judges a queued submission and stores progress as it goes
+func (s *JudgeService) processJob(ctx context.Context, job *judgeJob) error {
+	var submission database.Submission
+	if err := s.db.First(&submission, "id = ?", job.SubmissionID).Error; err != nil {
+		return err
+	}
+	if job.RecomputeWinner {
+		defer s.recomputeWinnerWhenJudged(submission.MatchID)
+	}
+
+	// Tests always come from the problem, never from the submitter
+	problem, err := s.matchProblem(submission.MatchID)
+	if err != nil {
+		return s.failSubmission(&submission, err)
+	}
+
+	// Reset any partial state left by a worker that died mid-job
+	submission.Status = "running"
+	submission.TestsDone = 0
+	submission.TestsTotal = len(problem.TestCases)
+	submission.TestResults = "[]"
+	submission.Subtasks = "[]"
+	if err := s.db.Omit("PendingRejudge").Save(&submission).Error; err != nil {
+		return err
+	}
+
+	var partial []TestCaseResult
+	progress := func(done int, result TestCaseResult) {
+		partial = append(partial, result)
+		testResults, _ := json.Marshal(partial)
+		s.db.Model(&database.Submission{}).Where("id = ?", submission.ID).Updates(map[string]interface{}{
+			"tests_done":   done,
+			"test_results": string(testResults),
+		})
+	}
+
+	result, err := s.judgeCode(ctx, submission.Code, submission.Language, problem, submission.Mode, progress)
+	if err != nil {
+		return s.failSubmission(&submission, err)
+	}
+
+	testResults, err := json.Marshal(result.TestCases)
+	if err != nil {
+		return err
+	}
+	subtasks, err := json.Marshal(result.Subtasks)
+	if err != nil {
+		return err
+	}
+
+	// Feedback on the code is extra, judging succeeded without it
+	var analysis []byte
+	if s.analysis {
+		if result.Analysis, err = s.analyze(ctx, submission.Code, submission.Language, problem); err != nil {
+			log.Printf("Analysis of submission %s failed: %v", submission.ID, err)
+		} else if analysis, err = json.Marshal(result.Analysis); err != nil {
+			return err
+		}
+	}
+
+	// Update submission with results
+	submission.Status = "done"
+	submission.Verdict = string(result.Verdict)
+	submission.Score = result.Score
+	submission.Runtime = result.Runtime
+	submission.Memory = result.Memory
+	submission.ErrorMsg = result.ErrorMsg
+	submission.TestResults = string(testResults)
+	submission.Subtasks = string(subtasks)
+	submission.TestsDone = len(result.TestCases)
+	submission.Analysis = string(analysis)
+	if err := s.db.Omit("PendingRejudge").Save(&submission).Error; err != nil {
+		return err
+	}
+
+	if s.plagiarism != nil {
+		if _, err := s.plagiarism.ScanSubmission(submission.ID); err != nil {
+			log.Printf("Plagiarism scan of submission %s failed: %v", submission.ID, err)
+		}
+	}
+	return nil
+}
+
+// failSubmission records that a submission could not be judged
+func (s *JudgeService) failSubmission(submission *database.Submission, err error) error {
+	submission.Status = "error"
+	submission.ErrorMsg = err.Error()
+	s.db.Omit("PendingRejudge").Save(submission)
+	return err
+}
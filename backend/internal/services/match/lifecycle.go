@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Match states. A new match waits for a player to join its room, then for
+// both players to confirm they are ready, counts down and is played until
+// its time runs out or a player scores 100. Its submissions are judged
+// before the winner is decided.
+const (
+	MatchMatched      = "matched"
+	MatchReadyCheck   = "ready_check"
+	MatchCountdown    = "countdown"
+	MatchActive       = "active"
+	MatchJudgingFinal = "judging_final"
+	MatchCompleted    = "completed"
+	MatchAborted      = "aborted"   // not played, nobody is rated
+	MatchForfeited    = "forfeited" // a player left while playing, the opponent wins
+)
+
+var (
+	ErrMatchNotActive    = errors.New("match is not in progress")
+	ErrInvalidTransition = errors.New("invalid match state change")
+)
+
+// matchTransitions lists the states each state may move to
+var matchTransitions = map[string][]string{
+	MatchMatched:      {MatchReadyCheck, MatchAborted},
+	MatchReadyCheck:   {MatchCountdown, MatchAborted},
+	MatchCountdown:    {MatchActive, MatchAborted},
+	MatchActive:       {MatchJudgingFinal, MatchForfeited},
+	MatchJudgingFinal: {MatchCompleted},
+}
+
+// timedStates are the states that end at the match's deadline
+var timedStates = []string{MatchMatched, MatchReadyCheck, MatchCountdown, MatchActive, MatchJudgingFinal}
+
+// finalJudgingTimeout bounds the wait for a finished match's submissions
+// to be judged, timerInterval is how often deadlines are checked
+const (
+	finalJudgingTimeout = 2 * time.Minute
+	timerInterval       = time.Second
+)
+
+// MatchTimers are the time limits of a match's states
+type MatchTimers struct {
+	ReadyTimeout time.Duration            // to join the room, and again to confirm
+	Countdown    time.Duration            // between the ready check and play
+	Durations    map[string]time.Duration // playing time by difficulty
+}
+
+var DefaultMatchTimers = MatchTimers{
+	ReadyTimeout: 30 * time.Second,
+	Countdown:    5 * time.Second,
+	Durations: map[string]time.Duration{
+		"easy":   15 * time.Minute,
+		"medium": 30 * time.Minute,
+		"hard":   45 * time.Minute,
+	},
+}
+
+// duration returns the playing time of a difficulty
+func (t MatchTimers) duration(difficulty string) time.Duration {
+	if duration := t.Durations[difficulty]; duration > 0 {
+		return duration
+	}
+	return DefaultMatchTimers.Durations["medium"]
+}
+
+// JoinMatch records that a player joined the match's room, which starts
+// the ready check of a new match
+func (s *MatchService) JoinMatch(ctx context.Context, matchID, playerID uuid.UUID) (*MatchData, error) {
+	return s.updatePlayerMatch(ctx, matchID, func(tx *gorm.DB, match *database.Match, now time.Time) error {
+		if playerID != match.Player1ID && playerID != match.Player2ID {
+			return ErrNotInMatch
+		}
+		if match.Status != MatchMatched {
+			return nil
+		}
+		return setMatchState(match, MatchReadyCheck, now, s.timers.ReadyTimeout)
+	})
+}
+
+// ReadyMatch records that a player is ready to play. The countdown starts
+// once both players are.
+func (s *MatchService) ReadyMatch(ctx context.Context, matchID, playerID uuid.UUID) (*MatchData, error) {
+	return s.updatePlayerMatch(ctx, matchID, func(tx *gorm.DB, match *database.Match, now time.Time) error {
+		if match.Status == MatchMatched {
+			if err := setMatchState(match, MatchReadyCheck, now, s.timers.ReadyTimeout); err != nil {
+				return err
+			}
+		}
+		if match.Status != MatchReadyCheck {
+			return fmt.Errorf("%w: match is %s", ErrInvalidTransition, match.Status)
+		}
+
+		switch playerID {
+		case match.Player1ID:
+			match.Player1Ready = true
+		case match.Player2ID:
+			match.Player2Ready = true
+		default:
+			return ErrNotInMatch
+		}
+		if !match.Player1Ready || !match.Player2Ready {
+			return nil
+		}
+		return setMatchState(match, MatchCountdown, now, s.timers.Countdown)
+	})
+}
+
+// ForfeitMatch lets a player leave a match. A match not started yet is
+// aborted, a match in play is won by the opponent.
+func (s *MatchService) ForfeitMatch(ctx context.Context, matchID, playerID uuid.UUID) (*MatchData, error) {
+	return s.updatePlayerMatch(ctx, matchID, func(tx *gorm.DB, match *database.Match, now time.Time) error {
+		var opponentID uuid.UUID
+		switch playerID {
+		case match.Player1ID:
+			opponentID = match.Player2ID
+		case match.Player2ID:
+			opponentID = match.Player1ID
+		default:
+			return ErrNotInMatch
+		}
+
+		if match.Status != MatchActive {
+			return setMatchState(match, MatchAborted, now, 0)
+		}
+		endPlay(match, now)
+		match.WinnerID = &opponentID
+		return setMatchState(match, MatchForfeited, now, 0)
+	})
+}
+
+// updatePlayerMatch changes a match on a player's behalf, see updateMatch
+func (s *MatchService) updatePlayerMatch(ctx context.Context, matchID uuid.UUID, change func(tx *gorm.DB, match *database.Match, now time.Time) error) (*MatchData, error) {
+	match, err := s.updateMatch(ctx, matchID, change)
+	if err != nil {
+		return nil, err
+	}
+	return newMatchData(match), nil
+}
+
+// RunTimers moves matches on when their state's time runs out, a player
+// scores 100 or the final submissions are judged, until ctx is cancelled
+func (s *MatchService) RunTimers(ctx context.Context) {
+	ticker := time.NewTicker(timerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.advanceMatches(ctx); err != nil {
+			log.Printf("Match timers: %v", err)
+		}
+	}
+}
+
+// advanceMatches moves on every match that is due
+func (s *MatchService) advanceMatches(ctx context.Context) error {
+	var matchIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&database.Match{}).
+		Where("status IN ? AND deadline <= ?", timedStates, time.Now()).
+		Or("status = ? AND EXISTS (SELECT 1 FROM submissions WHERE submissions.match_id = matches.id AND submissions.status = ? AND submissions.score >= 100)",
+			MatchActive, "done").
+		Or("status = ? AND NOT EXISTS (SELECT 1 FROM submissions WHERE submissions.match_id = matches.id AND submissions.status IN ?)",
+			MatchJudgingFinal, []string{"pending", "running"}).
+		Pluck("id", &matchIDs).Error; err != nil {
+		return err
+	}
+
+	for _, matchID := range matchIDs {
+		if _, err := s.updateMatch(ctx, matchID, s.advanceMatch); err != nil {
+			log.Printf("Match timers: match %s: %v", matchID, err)
+		}
+	}
+	return nil
+}
+
+// advanceMatch moves a match to its next state if it is due. Players who
+// did not get ready in time abort the match, and play ends at the deadline
+// or the first full score.
+func (s *MatchService) advanceMatch(tx *gorm.DB, match *database.Match, now time.Time) error {
+	due := match.Deadline != nil && !now.Before(*match.Deadline)
+
+	switch match.Status {
+	case MatchMatched, MatchReadyCheck:
+		if due {
+			return setMatchState(match, MatchAborted, now, 0)
+		}
+
+	case MatchCountdown:
+		if due {
+			match.StartedAt = &now
+			return setMatchState(match, MatchActive, now, s.timers.duration(match.Difficulty))
+		}
+
+	case MatchActive:
+		var fullScores int64
+		if err := tx.Model(&database.Submission{}).
+			Where("match_id = ? AND status = ? AND score >= 100", match.ID, "done").
+			Count(&fullScores).Error; err != nil {
+			return err
+		}
+		if due || fullScores > 0 {
+			endPlay(match, now)
+			return setMatchState(match, MatchJudgingFinal, now, finalJudgingTimeout)
+		}
+
+	case MatchJudgingFinal:
+		var waiting int64
+		if err := tx.Model(&database.Submission{}).
+			Where("match_id = ? AND status IN ?", match.ID, []string{"pending", "running"}).
+			Count(&waiting).Error; err != nil {
+			return err
+		}
+		if due || waiting == 0 {
+			winner, err := matchWinner(tx, match)
+			if err != nil {
+				return err
+			}
+			match.WinnerID = winner
+			return setMatchState(match, MatchCompleted, now, 0)
+		}
+	}
+	return nil
+}
+
+// updateMatch changes a match under a row lock, rates it when it was just
+// decided and announces its new state to the match's room
+func (s *MatchService) updateMatch(ctx context.Context, matchID uuid.UUID, change func(tx *gorm.DB, match *database.Match, now time.Time) error) (*database.Match, error) {
+	var match database.Match
+	var previous string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&match, "id = ?", matchID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMatchNotFound
+		} else if err != nil {
+			return err
+		}
+
+		previous = match.Status
+		if err := change(tx, &match, time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Model(&database.Match{}).Where("id = ?", match.ID).Updates(map[string]interface{}{
+			"status":        match.Status,
+			"deadline":      match.Deadline,
+			"player1_ready": match.Player1Ready,
+			"player2_ready": match.Player2Ready,
+			"started_at":    match.StartedAt,
+			"ended_at":      match.EndedAt,
+			"duration":      match.Duration,
+			"winner_id":     match.WinnerID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if match.Status != previous && (match.Status == MatchCompleted || match.Status == MatchForfeited) {
+			return rateMatch(tx, s.ratings, &match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if match.Status != previous {
+		s.publishMatchState(ctx, &match)
+	}
+	return &match, nil
+}
+
+// setMatchState moves a match to a state that ends after limit, 0 for a
+// state without a time limit
+func setMatchState(match *database.Match, state string, now time.Time, limit time.Duration) error {
+	allowed := false
+	for _, next := range matchTransitions[match.Status] {
+		allowed = allowed || next == state
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, match.Status, state)
+	}
+
+	match.Status = state
+	match.Deadline = nil
+	if limit > 0 {
+		deadline := now.Add(limit)
+		match.Deadline = &deadline
+	}
+	return nil
+}
+
+// endPlay records when a match stopped being played, at its deadline if
+// the timers ran late, and how long it was played
+func endPlay(match *database.Match, now time.Time) {
+	endedAt := now
+	if match.Deadline != nil && match.Deadline.Before(now) {
+		endedAt = *match.Deadline
+	}
+	match.EndedAt = &endedAt
+	if match.StartedAt != nil {
+		match.Duration = int(endedAt.Sub(*match.StartedAt).Seconds())
+	}
+}
+
+// publishMatchState announces a match's state and when it ends to its room
+func (s *MatchService) publishMatchState(ctx context.Context, match *database.Match) {
+	event := map[string]interface{}{
+		"type":      "match_state",
+		"match_id":  match.ID,
+		"status":    match.Status,
+		"deadline":  match.Deadline,
+		"winner_id": match.WinnerID,
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.redis.Publish(ctx, fmt.Sprintf("room:%s", match.ID), eventData)
+}
+
+// RoomEvents returns the events announced to a room, such as match_start
+// and match_state, as JSON until ctx is cancelled. The room is named with
+// or without its "room:" prefix.
+func (s *MatchService) RoomEvents(ctx context.Context, roomID string) <-chan []byte {
+	pubsub := s.redis.Subscribe(ctx, "room:"+strings.TrimPrefix(roomID, "room:"))
+	events := make(chan []byte)
+
+	// Wait for the subscription so no event sent after this returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Printf("Room %s events: %v", roomID, err)
+		pubsub.Close()
+		close(events)
+		return events
+	}
+	messages := pubsub.Channel()
+
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case events <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
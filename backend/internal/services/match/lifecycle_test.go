@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestSetMatchState(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{MatchMatched, MatchReadyCheck, true},
+		{MatchMatched, MatchAborted, true},
+		{MatchReadyCheck, MatchCountdown, true},
+		{MatchReadyCheck, MatchAborted, true},
+		{MatchCountdown, MatchActive, true},
+		{MatchCountdown, MatchAborted, true},
+		{MatchActive, MatchJudgingFinal, true},
+		{MatchActive, MatchForfeited, true},
+		{MatchJudgingFinal, MatchCompleted, true},
+
+		{MatchMatched, MatchActive, false},
+		{MatchReadyCheck, MatchActive, false},
+		{MatchActive, MatchAborted, false},
+		{MatchActive, MatchCompleted, false},
+		{MatchJudgingFinal, MatchForfeited, false},
+		{MatchCompleted, MatchActive, false},
+		{MatchAborted, MatchReadyCheck, false},
+		{MatchForfeited, MatchCompleted, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			deadline := now
+			match := &database.Match{Status: tt.from, Deadline: &deadline}
+			err := setMatchState(match, tt.to, now, time.Minute)
+
+			if !tt.allowed {
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("setMatchState error = %v, want ErrInvalidTransition", err)
+				}
+				if match.Status != tt.from || match.Deadline != &deadline {
+					t.Errorf("refused change left the match %s until %v", match.Status, match.Deadline)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setMatchState: %v", err)
+			}
+			if match.Status != tt.to || match.Deadline == nil || !match.Deadline.Equal(now.Add(time.Minute)) {
+				t.Errorf("match is %s until %v, want %s until %v", match.Status, match.Deadline, tt.to, now.Add(time.Minute))
+			}
+		})
+	}
+}
+
+func TestSetMatchStateWithoutLimit(t *testing.T) {
+	now := time.Now()
+	match := &database.Match{Status: MatchJudgingFinal, Deadline: &now}
+	if err := setMatchState(match, MatchCompleted, now, 0); err != nil {
+		t.Fatalf("setMatchState: %v", err)
+	}
+	if match.Deadline != nil {
+		t.Errorf("completed match has deadline %v, want none", match.Deadline)
+	}
+}
+
+func TestAdvanceMatch(t *testing.T) {
+	service := NewMatchService(nil)
+	service.SetTimers(MatchTimers{
+		ReadyTimeout: 30 * time.Second,
+		Countdown:    5 * time.Second,
+		Durations:    map[string]time.Duration{"easy": 15 * time.Minute},
+	})
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Second)
+
+	// States that need no submissions to move on
+	tests := []struct {
+		name       string
+		status     string
+		difficulty string
+		deadline   *time.Time
+		want       string
+		limit      time.Duration
+	}{
+		{"nobody joined", MatchMatched, "easy", &past, MatchAborted, 0},
+		{"waiting to join", MatchMatched, "easy", &future, MatchMatched, time.Second},
+		{"nobody ready", MatchReadyCheck, "easy", &past, MatchAborted, 0},
+		{"getting ready", MatchReadyCheck, "easy", &future, MatchReadyCheck, time.Second},
+		{"countdown over", MatchCountdown, "easy", &past, MatchActive, 15 * time.Minute},
+		{"counting down", MatchCountdown, "easy", &future, MatchCountdown, time.Second},
+		{"unknown difficulty plays medium", MatchCountdown, "extreme", &past, MatchActive, DefaultMatchTimers.Durations["medium"]},
+		{"finished", MatchCompleted, "easy", nil, MatchCompleted, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &database.Match{Status: tt.status, Difficulty: tt.difficulty, Deadline: tt.deadline}
+			if err := service.advanceMatch(nil, match, now); err != nil {
+				t.Fatalf("advanceMatch: %v", err)
+			}
+			if match.Status != tt.want {
+				t.Errorf("match is %s, want %s", match.Status, tt.want)
+			}
+
+			switch {
+			case tt.limit == 0 && match.Deadline != nil:
+				t.Errorf("match has deadline %v, want none", match.Deadline)
+			case tt.limit > 0 && (match.Deadline == nil || match.Deadline.Sub(now) != tt.limit):
+				t.Errorf("match has deadline %v, want in %v", match.Deadline, tt.limit)
+			}
+			if tt.want == MatchActive && (match.StartedAt == nil || !match.StartedAt.Equal(now)) {
+				t.Errorf("active match started at %v, want %v", match.StartedAt, now)
+			}
+		})
+	}
+}
+
+func TestEndPlay(t *testing.T) {
+	now := time.Now()
+	startedAt := now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name     string
+		deadline time.Time
+		endedAt  time.Time
+		duration int
+	}{
+		{"before the deadline", now.Add(time.Minute), now, 600},
+		{"timers ran late", now.Add(-time.Minute), now.Add(-time.Minute), 540},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &database.Match{StartedAt: &startedAt, Deadline: &tt.deadline}
+			endPlay(match, now)
+			if match.EndedAt == nil || !match.EndedAt.Equal(tt.endedAt) {
+				t.Errorf("match ended at %v, want %v", match.EndedAt, tt.endedAt)
+			}
+			if match.Duration != tt.duration {
+				t.Errorf("match lasted %ds, want %ds", match.Duration, tt.duration)
+			}
+		})
+	}
+}
+
+func TestRoomEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewMatchService(matchmakingRedis(t))
+
+	match := &database.Match{ID: uuid.New(), Status: MatchActive}
+	events := service.RoomEvents(ctx, match.ID.String())
+	service.publishMatchState(ctx, match)
+
+	select {
+	case payload := <-events:
+		var event map[string]interface{}
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		if event["type"] != "match_state" || event["status"] != MatchActive {
+			t.Errorf("event = %v, want match_state active", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event relayed")
+	}
+
+	cancel()
+	for range events {
+	}
+}
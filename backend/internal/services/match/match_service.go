@@ -2,8 +2,9 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"coderoulette/internal/database"
@@ -11,11 +12,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type MatchService struct {
-	redis *redis.Client
-	db    *gorm.DB
+	redis       *redis.Client
+	db          *gorm.DB
+	ratings     RatingSystem
+	matchmaking Matchmaking
+	timers      MatchTimers
+
+	// Picks the problem of new matches when set
+	problems *ProblemService
 }
 
 type MatchRequest struct {
@@ -30,11 +40,50 @@ type MatchResult struct {
 	Player2ID uuid.UUID `json:"player2_id"`
 	ProblemID uuid.UUID `json:"problem_id"`
 	RoomID    string    `json:"room_id"`
+
+	// Queue the match was made in, the first player's when preferences
+	// were relaxed
+	Difficulty string `json:"difficulty"`
+	Language   string `json:"language"`
+
+	Problem *ProblemData `json:"problem,omitempty"` // what players may see of it
+}
+
+// MatchData is a match as players may see it
+type MatchData struct {
+	ID         uuid.UUID  `json:"id"`
+	Player1ID  uuid.UUID  `json:"player1_id"`
+	Player2ID  uuid.UUID  `json:"player2_id"`
+	ProblemID  uuid.UUID  `json:"problem_id"`
+	Status     string     `json:"status"`
+	WinnerID   *uuid.UUID `json:"winner_id"`
+	Duration   int        `json:"duration"` // in seconds
+	Difficulty string     `json:"difficulty"`
+	Language   string     `json:"language"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Deadline     *time.Time `json:"deadline"`
+	Player1Ready bool       `json:"player1_ready"`
+	Player2Ready bool       `json:"player2_ready"`
+	StartedAt    *time.Time `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+
+	Player1RatingDelta int `json:"player1_rating_delta"`
+	Player2RatingDelta int `json:"player2_rating_delta"`
+
+	// Set when loaded, the problem without its hidden parts
+	Player1 *database.User `json:"player1,omitempty"`
+	Player2 *database.User `json:"player2,omitempty"`
+	Problem *ProblemData   `json:"problem,omitempty"`
 }
 
 func NewMatchService(redis *redis.Client) *MatchService {
 	return &MatchService{
-		redis: redis,
+		redis:       redis,
+		ratings:     Glicko2{Tau: 0.5},
+		matchmaking: DefaultMatchmaking,
+		timers:      DefaultMatchTimers,
 	}
 }
 
@@ -42,139 +91,237 @@ func (s *MatchService) SetDB(db *gorm.DB) {
 	s.db = db
 }
 
-// QueueUser adds a user to the matchmaking queue
-func (s *MatchService) QueueUser(ctx context.Context, req *MatchRequest) error {
-	queueKey := fmt.Sprintf("queue:%s:%s", req.Difficulty, req.Language)
-
-	// Add user to queue with timestamp
-	queueData := map[string]interface{}{
-		"user_id":    req.UserID.String(),
-		"timestamp":  time.Now().Unix(),
-		"difficulty": req.Difficulty,
-		"language":   req.Language,
-	}
+func (s *MatchService) SetRatingSystem(ratings RatingSystem) {
+	s.ratings = ratings
+}
 
-	data, err := json.Marshal(queueData)
-	if err != nil {
-		return err
-	}
+func (s *MatchService) SetMatchmaking(matchmaking Matchmaking) {
+	s.matchmaking = matchmaking
+}
 
-	// Add to sorted set with timestamp as score for FIFO ordering
-	return s.redis.ZAdd(ctx, queueKey, redis.Z{
-		Score:  float64(time.Now().Unix()),
-		Member: data,
-	}).Err()
+func (s *MatchService) SetTimers(timers MatchTimers) {
+	s.timers = timers
 }
 
-// FindMatch attempts to find a match for the user
-func (s *MatchService) FindMatch(ctx context.Context, req *MatchRequest) (*MatchResult, error) {
-	queueKey := fmt.Sprintf("queue:%s:%s", req.Difficulty, req.Language)
+func (s *MatchService) SetProblemService(problems *ProblemService) {
+	s.problems = problems
+}
 
-	// Get all users in queue
-	users, err := s.redis.ZRange(ctx, queueKey, 0, 10).Result()
-	if err != nil {
+// GetMatchStatus returns the current status of a match with its players
+// and what they may see of its problem
+func (s *MatchService) GetMatchStatus(ctx context.Context, matchID uuid.UUID) (*MatchData, error) {
+	var match database.Match
+	if err := s.db.Preload("Player1").Preload("Player2").Preload("Problem").First(&match, "id = ?", matchID).Error; err != nil {
 		return nil, err
 	}
 
-	if len(users) < 2 {
-		return nil, nil // No match found yet
-	}
-
-	// Parse first two users
-	var user1, user2 map[string]interface{}
-	if err := json.Unmarshal([]byte(users[0]), &user1); err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal([]byte(users[1]), &user2); err != nil {
-		return nil, err
+	data := newMatchData(&match)
+	data.Player1 = &match.Player1
+	data.Player2 = &match.Player2
+	if match.ProblemID != uuid.Nil {
+		problem, err := newProblemData(&match.Problem)
+		if err != nil {
+			return nil, err
+		}
+		data.Problem = problem.Public()
 	}
+	return data, nil
+}
 
-	// Remove matched users from queue
-	s.redis.ZRem(ctx, queueKey, users[0], users[1])
-
-	// Create match
-	matchID := uuid.New()
-	player1ID, _ := uuid.Parse(user1["user_id"].(string))
-	player2ID, _ := uuid.Parse(user2["user_id"].(string))
-
-	// Create match record in database
-	match := &database.Match{
-		ID:        matchID,
-		Player1ID: player1ID,
-		Player2ID: player2ID,
-		Status:    "waiting",
+func newMatchData(match *database.Match) *MatchData {
+	return &MatchData{
+		ID:                 match.ID,
+		Player1ID:          match.Player1ID,
+		Player2ID:          match.Player2ID,
+		ProblemID:          match.ProblemID,
+		Status:             match.Status,
+		WinnerID:           match.WinnerID,
+		Duration:           match.Duration,
+		Difficulty:         match.Difficulty,
+		Language:           match.Language,
+		CreatedAt:          match.CreatedAt,
+		UpdatedAt:          match.UpdatedAt,
+		Deadline:           match.Deadline,
+		Player1Ready:       match.Player1Ready,
+		Player2Ready:       match.Player2Ready,
+		StartedAt:          match.StartedAt,
+		EndedAt:            match.EndedAt,
+		Player1RatingDelta: match.Player1RatingDelta,
+		Player2RatingDelta: match.Player2RatingDelta,
 	}
+}
 
-	if err := s.db.Create(match).Error; err != nil {
-		return nil, err
-	}
+// recomputeWinner sets the winner of a completed match from its judged
+// submissions again, see matchWinner. When the winner changes the match is
+// rated again. It reports whether the winner changed.
+func recomputeWinner(db *gorm.DB, ratings RatingSystem, matchID uuid.UUID) (bool, error) {
+	changed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var match database.Match
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&match, "id = ?", matchID).Error; err != nil {
+			return err
+		}
+		if match.Status != MatchCompleted {
+			return nil
+		}
 
-	// Generate room ID for WebSocket
-	roomID := fmt.Sprintf("room:%s", matchID.String())
+		winner, err := matchWinner(tx, &match)
+		if err != nil {
+			return err
+		}
 
-	return &MatchResult{
-		MatchID:   matchID,
-		Player1ID: player1ID,
-		Player2ID: player2ID,
-		RoomID:    roomID,
-	}, nil
+		if winner == nil && match.WinnerID == nil ||
+			winner != nil && match.WinnerID != nil && *winner == *match.WinnerID {
+			return nil
+		}
+		changed = true
+		match.WinnerID = winner
+		if err := tx.Model(&database.Match{}).Where("id = ?", matchID).Update("winner_id", winner).Error; err != nil {
+			return err
+		}
+		return rateMatch(tx, ratings, &match)
+	})
+	return changed, err
 }
 
-// GetMatchStatus returns the current status of a match
-func (s *MatchService) GetMatchStatus(ctx context.Context, matchID uuid.UUID) (*database.Match, error) {
-	var match database.Match
-	if err := s.db.Preload("Player1").Preload("Player2").Preload("Problem").First(&match, "id = ?", matchID).Error; err != nil {
+// matchWinner returns the winner of a match from the judged submissions
+// made while it was played: the player with the higher best score wins, a
+// tie goes to whoever reached it first and nobody wins if no one scored
+func matchWinner(tx *gorm.DB, match *database.Match) (*uuid.UUID, error) {
+	query := tx.Where("match_id = ? AND status = ?", match.ID, "done")
+	if match.EndedAt != nil {
+		query = query.Where("created_at <= ?", *match.EndedAt)
+	}
+	var submissions []database.Submission
+	if err := query.Order("created_at ASC").Find(&submissions).Error; err != nil {
 		return nil, err
 	}
-	return &match, nil
-}
 
-// UpdateMatchStatus updates the status of a match
-func (s *MatchService) UpdateMatchStatus(ctx context.Context, matchID uuid.UUID, status string) error {
-	return s.db.Model(&database.Match{}).Where("id = ?", matchID).Update("status", status).Error
+	var winner *uuid.UUID
+	best := 0
+	for i := range submissions {
+		// Earlier submissions come first, so only a strictly higher
+		// score takes the lead
+		if submissions[i].Score > best {
+			best = submissions[i].Score
+			winner = &submissions[i].PlayerID
+		}
+	}
+	return winner, nil
 }
 
-// CompleteMatch marks a match as completed and sets the winner
-func (s *MatchService) CompleteMatch(ctx context.Context, matchID uuid.UUID, winnerID *uuid.UUID, duration int) error {
-	updates := map[string]interface{}{
-		"status":   "completed",
-		"duration": duration,
+// rateMatch rates both players of a completed match from its winner. A
+// match rated before is rated again from the ratings the players had
+// before it, and the difference is applied to their current ratings, so
+// matches they played since keep their effect.
+func rateMatch(tx *gorm.DB, ratings RatingSystem, match *database.Match) error {
+	var players []database.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uuid.UUID{match.Player1ID, match.Player2ID}).
+		Find(&players).Error; err != nil {
+		return err
+	}
+	if len(players) != 2 {
+		return fmt.Errorf("match %s: players not found", match.ID)
+	}
+	if players[0].ID != match.Player1ID {
+		players[0], players[1] = players[1], players[0]
 	}
 
-	if winnerID != nil {
-		updates["winner_id"] = winnerID
+	var histories []database.RatingHistory
+	if err := tx.Where("match_id = ?", match.ID).Find(&histories).Error; err != nil {
+		return err
+	}
+	previous := make(map[uuid.UUID]*database.RatingHistory, len(histories))
+	for i := range histories {
+		previous[histories[i].UserID] = &histories[i]
 	}
 
-	return s.db.Model(&database.Match{}).Where("id = ?", matchID).Updates(updates).Error
-}
+	before := make([]PlayerRating, 2)
+	for i, player := range players {
+		if history, ok := previous[player.ID]; ok {
+			before[i] = PlayerRating{Rating: float64(history.RatingBefore), Deviation: history.DeviationBefore, Volatility: history.VolatilityBefore}
+		} else {
+			before[i] = withDefaults(PlayerRating{Rating: float64(player.Rating), Deviation: player.RatingDeviation, Volatility: player.Volatility})
+		}
+	}
 
-// GetQueueStatus returns the number of users waiting in queue
-func (s *MatchService) GetQueueStatus(ctx context.Context, difficulty, language string) (int, error) {
-	queueKey := fmt.Sprintf("queue:%s:%s", difficulty, language)
-	count, err := s.redis.ZCard(ctx, queueKey).Result()
-	return int(count), err
-}
+	deltas := make([]int, 2)
+	for i, player := range players {
+		result, score := matchResult(match, player.ID)
+		after := ratings.Rate(before[i], before[1-i], score)
+
+		current := withDefaults(PlayerRating{Rating: float64(player.Rating), Deviation: player.RatingDeviation, Volatility: player.Volatility})
+		wins, losses := player.Wins, player.Losses
+
+		history := previous[player.ID]
+		if history == nil {
+			history = &database.RatingHistory{
+				UserID:           player.ID,
+				MatchID:          match.ID,
+				RatingBefore:     int(before[i].Rating),
+				DeviationBefore:  before[i].Deviation,
+				VolatilityBefore: before[i].Volatility,
+			}
+		} else {
+			// Undo what the earlier rating of this match did
+			current.Rating -= float64(history.RatingDelta)
+			current.Deviation -= history.Deviation - history.DeviationBefore
+			current.Volatility -= history.Volatility - history.VolatilityBefore
+			wins, losses = countResult(wins, losses, history.Result, -1)
+		}
 
-// RemoveFromQueue removes a user from the matchmaking queue
-func (s *MatchService) RemoveFromQueue(ctx context.Context, userID uuid.UUID, difficulty, language string) error {
-	queueKey := fmt.Sprintf("queue:%s:%s", difficulty, language)
+		history.Result = result
+		history.Rating = int(math.Round(after.Rating))
+		history.RatingDelta = history.Rating - history.RatingBefore
+		history.Deviation = after.Deviation
+		history.Volatility = after.Volatility
+		if err := tx.Save(history).Error; err != nil {
+			return err
+		}
 
-	// Get all users in queue and find the one to remove
-	users, err := s.redis.ZRange(ctx, queueKey, 0, -1).Result()
-	if err != nil {
-		return err
+		current.Rating += float64(history.RatingDelta)
+		current.Deviation += history.Deviation - history.DeviationBefore
+		current.Volatility += history.Volatility - history.VolatilityBefore
+		wins, losses = countResult(wins, losses, result, 1)
+		if err := tx.Model(&database.User{}).Where("id = ?", player.ID).Updates(map[string]interface{}{
+			"rating":           int(current.Rating),
+			"rating_deviation": math.Min(math.Max(current.Deviation, minDeviation), maxDeviation),
+			"volatility":       math.Max(current.Volatility, 0),
+			"wins":             wins,
+			"losses":           losses,
+		}).Error; err != nil {
+			return err
+		}
+		deltas[i] = history.RatingDelta
 	}
 
-	for _, userData := range users {
-		var user map[string]interface{}
-		if err := json.Unmarshal([]byte(userData), &user); err != nil {
-			continue
-		}
+	return tx.Model(&database.Match{}).Where("id = ?", match.ID).Updates(map[string]interface{}{
+		"player1_rating_delta": deltas[0],
+		"player2_rating_delta": deltas[1],
+	}).Error
+}
 
-		if user["user_id"].(string) == userID.String() {
-			return s.redis.ZRem(ctx, queueKey, userData).Err()
-		}
+// matchResult returns a player's result in a match and its score for
+// rating: 1 for a win, 0.5 for a draw and 0 for a loss
+func matchResult(match *database.Match, playerID uuid.UUID) (string, float64) {
+	switch {
+	case match.WinnerID == nil:
+		return "draw", 0.5
+	case *match.WinnerID == playerID:
+		return "win", 1
+	default:
+		return "loss", 0
 	}
+}
 
-	return nil
+// countResult adds n times a result to a player's wins and losses
+func countResult(wins, losses int, result string, n int) (int, int) {
+	switch result {
+	case "win":
+		wins += n
+	case "loss":
+		losses += n
+	}
+	return wins, losses
 }
@@ -0,0 +1,653 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Matchmaking tunes which queued players are paired. A player accepts
+// opponents within RatingGap of their rating, a gap that grows by
+// GapPerSecond while they wait up to MaxRatingGap, and two players are
+// paired when each accepts the other.
+type Matchmaking struct {
+	RatingGap    int
+	GapPerSecond int
+	MaxRatingGap int
+
+	// After waiting this long players also accept opponents who queued for
+	// another difficulty or language and waited as long, 0 never
+	RelaxDifficultyAfter time.Duration
+	RelaxLanguageAfter   time.Duration
+}
+
+var DefaultMatchmaking = Matchmaking{
+	RatingGap:            100,
+	GapPerSecond:         10,
+	MaxRatingGap:         800,
+	RelaxDifficultyAfter: time.Minute,
+}
+
+// difficulties players may queue for
+var difficulties = map[string]bool{"easy": true, "medium": true, "hard": true}
+
+// Matchmaking keys: each queue is a sorted set of user IDs by queueing
+// time, queuedUsersKey maps queued users to their queue so a user waits in
+// one queue at a time, or to the match they were put in by someone else's
+// request until they pick it up, queuedRatingsKey holds their ratings and
+// queuesKey lists the queues in use.
+//
+// Scripts only touch the keys they are given, the queue a user leaves and
+// the match they were paired to are kept in queuedUsersKey for that.
+const (
+	queuedUsersKey   = "queue:users"
+	queuedRatingsKey = "queue:ratings"
+	queuesKey        = "queue:queues"
+	pairedTTL        = 10 * time.Minute
+)
+
+// pairScanLimit bounds the longest waiting players considered per queue,
+// recentWaits the waits kept per queue for estimates and recentProblems
+// the matches per player whose problems new matches avoid
+const (
+	pairScanLimit  = 200
+	recentWaits    = 50
+	recentProblems = 20
+)
+
+// pairedPrefix starts the queuedUsersKey value of a paired user, followed
+// by the match and when it expires in milliseconds
+const pairedPrefix = "match:"
+
+// queueScript adds a user to a queue, leaving the queue they waited in
+// before. A user already waiting keeps their place, a user paired by
+// someone else is not queued until they picked up their match.
+//
+// KEYS: the queue, queued users, queued ratings, queues, the queue the
+// user waits in or the queue itself
+// ARGV: user, now, rating, when the user joined, times in milliseconds
+//
+// It returns 1 if the user is queued, 0 if they are paired and -1 if they
+// moved to a queue other than KEYS[5] meanwhile.
+var queueScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[2], ARGV[1])
+if current and string.sub(current, 1, 6) == "match:" then
+	local expires = tonumber(string.match(current, ":(%d+)$"))
+	if expires and expires > tonumber(ARGV[2]) then
+		return 0
+	end
+	current = false
+end
+if (current or KEYS[1]) ~= KEYS[5] then
+	return -1
+end
+if KEYS[5] ~= KEYS[1] then
+	redis.call("ZREM", KEYS[5], ARGV[1])
+end
+redis.call("HSET", KEYS[2], ARGV[1], KEYS[1])
+redis.call("HSET", KEYS[3], ARGV[1], ARGV[3])
+redis.call("SADD", KEYS[4], KEYS[1])
+redis.call("ZADD", KEYS[1], "NX", ARGV[4], ARGV[1])
+return 1
+`)
+
+// pairScript takes a waiting user and their closest rated acceptable
+// opponent out of the queues in one step, so no user is paired twice, and
+// records the match for the opponent. Queues after the user's own are only
+// searched, in order, once both players waited their relax time.
+//
+// KEYS: queued users, queued ratings, the user's queue, other queues
+// ARGV: user, the opponent's paired value, now, rating gap, gap per
+// second, max rating gap, scan limit, then the other queues' relax times,
+// all times in milliseconds
+//
+// It returns the opponent, their queue and how long each player waited,
+// or nothing if the user is no longer waiting or has no one to play.
+var pairScript = redis.NewScript(`
+local user, now = ARGV[1], tonumber(ARGV[3])
+local joined = redis.call("ZSCORE", KEYS[3], user)
+if not joined then
+	return false
+end
+joined = tonumber(joined)
+
+local gap, perSecond, maxGap = tonumber(ARGV[4]), tonumber(ARGV[5]), tonumber(ARGV[6])
+local function window(since)
+	return math.min(gap + perSecond * (now - since) / 1000, maxGap)
+end
+local rating = tonumber(redis.call("HGET", KEYS[2], user)) or 0
+
+local best, bestQueue, bestGap, bestSince
+for i = 3, #KEYS do
+	local relaxAfter = 0
+	if i > 3 then
+		relaxAfter = tonumber(ARGV[i + 4])
+	end
+	if best or now - joined < relaxAfter then
+		break
+	end
+	local waiting = redis.call("ZRANGE", KEYS[i], 0, tonumber(ARGV[7]) - 1, "WITHSCORES")
+	for j = 1, #waiting, 2 do
+		local other, since = waiting[j], tonumber(waiting[j + 1])
+		if other ~= user and now - since >= relaxAfter then
+			local otherRating = tonumber(redis.call("HGET", KEYS[2], other)) or rating
+			local otherGap = math.abs(rating - otherRating)
+			if otherGap <= math.min(window(joined), window(since)) and (not best or otherGap < bestGap) then
+				best, bestQueue, bestGap, bestSince = other, KEYS[i], otherGap, since
+			end
+		end
+	end
+end
+if not best then
+	return false
+end
+
+redis.call("ZREM", KEYS[3], user)
+redis.call("ZREM", bestQueue, best)
+redis.call("HDEL", KEYS[1], user)
+redis.call("HSET", KEYS[1], best, ARGV[2])
+redis.call("HDEL", KEYS[2], user, best)
+return {best, bestQueue, now - joined, now - bestSince}
+`)
+
+// dequeueScript removes a user from a queue
+var dequeueScript = redis.NewScript(`
+redis.call("ZREM", KEYS[1], ARGV[1])
+if redis.call("HGET", KEYS[2], ARGV[1]) == KEYS[1] then
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("HDEL", KEYS[3], ARGV[1])
+end
+return 1
+`)
+
+// forgetPairedScript forgets the paired value of a user if it is still
+// the given one
+//
+// KEYS: queued users
+// ARGV: user, paired value
+var forgetPairedScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call("HDEL", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+func queueKey(difficulty, language string) string {
+	return fmt.Sprintf("queue:%s:%s", difficulty, language)
+}
+
+// parseQueueKey returns the difficulty and language of a queue
+func parseQueueKey(key string) (string, string, bool) {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) != 3 || parts[0] != "queue" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func waitsKey(difficulty, language string) string {
+	return fmt.Sprintf("queue:waits:%s:%s", difficulty, language)
+}
+
+// pairedValue records in queuedUsersKey that a user was put in a match,
+// which they must pick up before it expires
+func pairedValue(matchID uuid.UUID, expires time.Time) string {
+	return fmt.Sprintf("%s%s:%d", pairedPrefix, matchID, expires.UnixMilli())
+}
+
+// parsePairedValue returns the match of a paired value and whether it is
+// still to be picked up at now
+func parsePairedValue(value string, now time.Time) (uuid.UUID, bool) {
+	parts := strings.Split(strings.TrimPrefix(value, pairedPrefix), ":")
+	if len(parts) != 2 {
+		return uuid.Nil, false
+	}
+	matchID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	var expires int64
+	if _, err := fmt.Sscan(parts[1], &expires); err != nil || expires <= now.UnixMilli() {
+		return uuid.Nil, false
+	}
+	return matchID, true
+}
+
+// relaxAfter returns how long players must wait before a player queued for
+// one difficulty and language accepts an opponent queued for another, and
+// false if never
+func (m Matchmaking) relaxAfter(difficulty, language, otherDifficulty, otherLanguage string) (time.Duration, bool) {
+	var after time.Duration
+	if difficulty != otherDifficulty {
+		if m.RelaxDifficultyAfter <= 0 {
+			return 0, false
+		}
+		after = max(after, m.RelaxDifficultyAfter)
+	}
+	if language != otherLanguage {
+		if m.RelaxLanguageAfter <= 0 {
+			return 0, false
+		}
+		after = max(after, m.RelaxLanguageAfter)
+	}
+	return after, true
+}
+
+// QueueUser adds a user to the matchmaking queue of a difficulty and
+// language. Queueing again keeps the user's place; queueing for another
+// difficulty or language moves the user there. Users are not queued for
+// matches that could not be given a problem, ErrProblemNotFound.
+func (s *MatchService) QueueUser(ctx context.Context, req *MatchRequest) error {
+	if !difficulties[req.Difficulty] {
+		return fmt.Errorf("%w: unknown difficulty %q", ErrInvalidInput, req.Difficulty)
+	}
+
+	rating, err := s.userRating(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if s.problems != nil {
+		playable, err := s.problems.HasPlayableProblem(req.Difficulty, req.Language)
+		if err != nil {
+			return err
+		}
+		if !playable {
+			// The last problem may have gone while the user waited
+			s.RemoveFromQueue(ctx, req.UserID, req.Difficulty, req.Language)
+			return ErrProblemNotFound
+		}
+	}
+
+	_, err = s.queue(ctx, req, rating, time.Now())
+	return err
+}
+
+// requeue puts a user back in their queue as if they never left it since
+// joined
+func (s *MatchService) requeue(ctx context.Context, req *MatchRequest, joined time.Time) error {
+	rating, err := s.userRating(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = s.queue(ctx, req, rating, joined)
+	return err
+}
+
+func (s *MatchService) userRating(ctx context.Context, userID uuid.UUID) (int, error) {
+	var user database.User
+	if err := s.db.WithContext(ctx).Select("rating").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrUserNotFound
+		}
+		return 0, err
+	}
+	return user.Rating, nil
+}
+
+// queueAttempts bounds how often queueing is retried when the user moves
+// to another queue meanwhile
+const queueAttempts = 5
+
+// queue adds a user with a rating to their queue, waiting since joined
+// unless they already wait there. It returns false if the user is not
+// queued because they were paired and must pick up their match first.
+func (s *MatchService) queue(ctx context.Context, req *MatchRequest, rating int, joined time.Time) (bool, error) {
+	own := queueKey(req.Difficulty, req.Language)
+	userID := req.UserID.String()
+
+	for attempt := 0; attempt < queueAttempts; attempt++ {
+		// The queue the user leaves must be given to the script
+		previous, err := s.redis.HGet(ctx, queuedUsersKey, userID).Result()
+		if err != nil && err != redis.Nil {
+			return false, err
+		}
+		if _, _, ok := parseQueueKey(previous); !ok {
+			previous = own
+		}
+
+		queued, err := queueScript.Run(ctx, s.redis,
+			[]string{own, queuedUsersKey, queuedRatingsKey, queuesKey, previous},
+			userID, time.Now().UnixMilli(), rating, joined.UnixMilli(),
+		).Int()
+		if err != nil {
+			return false, err
+		}
+		if queued >= 0 {
+			return queued == 1, nil
+		}
+	}
+	return false, fmt.Errorf("user %s keeps changing queues", userID)
+}
+
+// FindMatch pairs the user with the closest rated player whose rating gap
+// both accept, the longest waiting on ties, and creates the match. It
+// returns nil if the user has no opponent yet, or was already paired by the
+// opponent's request, see PairedMatch.
+func (s *MatchService) FindMatch(ctx context.Context, req *MatchRequest) (*MatchResult, error) {
+	matchID := uuid.New()
+	paired, err := s.pair(ctx, req, matchID)
+	if err != nil || paired == nil {
+		return nil, err
+	}
+
+	pipe := s.redis.Pipeline()
+	for _, waited := range []struct {
+		key  string
+		wait time.Duration
+	}{
+		{waitsKey(req.Difficulty, req.Language), paired.at.Sub(paired.joined)},
+		{waitsKey(paired.difficulty, paired.language), paired.at.Sub(paired.opponentJoined)},
+	} {
+		pipe.LPush(ctx, waited.key, waited.wait.Milliseconds())
+		pipe.LTrim(ctx, waited.key, 0, recentWaits-1)
+	}
+	pipe.Exec(ctx)
+
+	// The opponent waited longer, plays first and chose the queue
+	match := &database.Match{
+		ID:         matchID,
+		Player1ID:  paired.opponentID,
+		Player2ID:  req.UserID,
+		Status:     MatchMatched,
+		Difficulty: paired.difficulty,
+		Language:   paired.language,
+	}
+	deadline := time.Now().Add(s.timers.ReadyTimeout)
+	match.Deadline = &deadline
+	problem, err := s.assignProblem(ctx, match)
+	if err == nil {
+		err = s.db.WithContext(ctx).Create(match).Error
+	}
+	if err != nil {
+		// Put both players back where they waited rather than lose them
+		s.forgetPaired(ctx, paired.opponentID, paired.value)
+		s.requeue(ctx, &MatchRequest{UserID: paired.opponentID, Difficulty: paired.difficulty, Language: paired.language}, paired.opponentJoined)
+		s.requeue(ctx, req, paired.joined)
+		return nil, err
+	}
+
+	result := newMatchResult(match, problem)
+	s.publishMatchStart(ctx, result)
+	return result, nil
+}
+
+// pairing is a user taken out of the queues with their opponent
+type pairing struct {
+	opponentID             uuid.UUID
+	difficulty, language   string // of the opponent's queue
+	at                     time.Time
+	joined, opponentJoined time.Time // when each player queued
+	value                  string    // recorded for the opponent
+}
+
+// pair takes a user and their opponent out of the queues, recording the
+// match for the opponent, or returns nil if the user has no opponent yet
+func (s *MatchService) pair(ctx context.Context, req *MatchRequest, matchID uuid.UUID) (*pairing, error) {
+	own := queueKey(req.Difficulty, req.Language)
+
+	// Other queues the user may be paired from, cheapest to relax first
+	queues, err := s.redis.SMembers(ctx, queuesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	type relaxedQueue struct {
+		key   string
+		after time.Duration
+	}
+	var relaxed []relaxedQueue
+	for _, key := range queues {
+		difficulty, language, ok := parseQueueKey(key)
+		if !ok || key == own {
+			continue
+		}
+		if after, ok := s.matchmaking.relaxAfter(req.Difficulty, req.Language, difficulty, language); ok {
+			relaxed = append(relaxed, relaxedQueue{key: key, after: after})
+		}
+	}
+	sort.SliceStable(relaxed, func(i, j int) bool { return relaxed[i].after < relaxed[j].after })
+
+	now := time.Now()
+	value := pairedValue(matchID, now.Add(pairedTTL))
+	keys := []string{queuedUsersKey, queuedRatingsKey, own}
+	args := []interface{}{
+		req.UserID.String(), value, now.UnixMilli(),
+		s.matchmaking.RatingGap, s.matchmaking.GapPerSecond, s.matchmaking.MaxRatingGap, pairScanLimit,
+	}
+	for _, queue := range relaxed {
+		keys = append(keys, queue.key)
+		args = append(args, queue.after.Milliseconds())
+	}
+
+	values, err := pairScript.Run(ctx, s.redis, keys, args...).Slice()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected pairing result %v", values)
+	}
+	opponent, _ := values[0].(string)
+	opponentQueue, _ := values[1].(string)
+	wait, _ := values[2].(int64)
+	opponentWait, _ := values[3].(int64)
+
+	opponentID, err := uuid.Parse(opponent)
+	if err != nil {
+		return nil, err
+	}
+	difficulty, language, ok := parseQueueKey(opponentQueue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected queue %q", opponentQueue)
+	}
+
+	return &pairing{
+		opponentID:     opponentID,
+		difficulty:     difficulty,
+		language:       language,
+		at:             now,
+		joined:         now.Add(-time.Duration(wait) * time.Millisecond),
+		opponentJoined: now.Add(-time.Duration(opponentWait) * time.Millisecond),
+		value:          value,
+	}, nil
+}
+
+// assignProblem picks a match's problem for its difficulty and language,
+// avoiding problems either player had in their recent matches
+func (s *MatchService) assignProblem(ctx context.Context, match *database.Match) (*ProblemData, error) {
+	if s.problems == nil {
+		return nil, nil
+	}
+
+	var recent []uuid.UUID
+	for _, playerID := range []uuid.UUID{match.Player1ID, match.Player2ID} {
+		var problemIDs []uuid.UUID
+		if err := s.db.WithContext(ctx).Model(&database.Match{}).
+			Where("player1_id = ? OR player2_id = ?", playerID, playerID).
+			Order("created_at DESC").
+			Limit(recentProblems).
+			Pluck("problem_id", &problemIDs).Error; err != nil {
+			return nil, err
+		}
+		recent = append(recent, problemIDs...)
+	}
+
+	problem, err := s.problems.PickProblem(match.Difficulty, match.Language, recent)
+	if err != nil {
+		return nil, err
+	}
+	match.ProblemID = problem.ID
+	return problem, nil
+}
+
+// publishMatchStart announces a new match to its room
+func (s *MatchService) publishMatchStart(ctx context.Context, result *MatchResult) {
+	event := map[string]interface{}{
+		"type":     "match_start",
+		"match_id": result.MatchID,
+		"match":    result,
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.redis.Publish(ctx, result.RoomID, eventData)
+}
+
+// PairedMatch returns, once, the match a waiting user was put in by their
+// opponent's request, or nil if there is none or it is still being created
+func (s *MatchService) PairedMatch(ctx context.Context, userID uuid.UUID) (*MatchResult, error) {
+	matchID, value, err := s.pairedMatch(ctx, userID)
+	if err != nil || matchID == uuid.Nil {
+		return nil, err
+	}
+
+	// The pairing shows before its match is created, the user keeps waiting
+	// until it is
+	result, err := s.loadMatchResult(ctx, matchID)
+	if errors.Is(err, ErrMatchNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.forgetPaired(ctx, userID, value); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// pairedMatch returns the match a user was paired to and its paired value,
+// or uuid.Nil
+func (s *MatchService) pairedMatch(ctx context.Context, userID uuid.UUID) (uuid.UUID, string, error) {
+	value, err := s.redis.HGet(ctx, queuedUsersKey, userID.String()).Result()
+	if err == redis.Nil {
+		return uuid.Nil, "", nil
+	} else if err != nil {
+		return uuid.Nil, "", err
+	}
+	if !strings.HasPrefix(value, pairedPrefix) {
+		return uuid.Nil, "", nil
+	}
+	matchID, ok := parsePairedValue(value, time.Now())
+	if !ok {
+		return uuid.Nil, "", nil
+	}
+	return matchID, value, nil
+}
+
+// forgetPaired forgets that a user was paired, if value still records it
+func (s *MatchService) forgetPaired(ctx context.Context, userID uuid.UUID, value string) error {
+	return forgetPairedScript.Run(ctx, s.redis, []string{queuedUsersKey}, userID.String(), value).Err()
+}
+
+// RoomMatch returns the match played in a room, as announced when it
+// started
+func (s *MatchService) RoomMatch(ctx context.Context, roomID string) (*MatchResult, error) {
+	matchID, err := uuid.Parse(strings.TrimPrefix(roomID, "room:"))
+	if err != nil {
+		return nil, ErrMatchNotFound
+	}
+	return s.loadMatchResult(ctx, matchID)
+}
+
+func (s *MatchService) loadMatchResult(ctx context.Context, matchID uuid.UUID) (*MatchResult, error) {
+	var match database.Match
+	if err := s.db.WithContext(ctx).First(&match, "id = ?", matchID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMatchNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var problem *ProblemData
+	if s.problems != nil && match.ProblemID != uuid.Nil {
+		var err error
+		if problem, err = s.problems.GetProblemByID(match.ProblemID); err != nil {
+			return nil, err
+		}
+	}
+	return newMatchResult(&match, problem), nil
+}
+
+func newMatchResult(match *database.Match, problem *ProblemData) *MatchResult {
+	result := &MatchResult{
+		MatchID:    match.ID,
+		Player1ID:  match.Player1ID,
+		Player2ID:  match.Player2ID,
+		ProblemID:  match.ProblemID,
+		RoomID:     fmt.Sprintf("room:%s", match.ID.String()),
+		Difficulty: match.Difficulty,
+		Language:   match.Language,
+	}
+	if problem != nil {
+		result.Problem = problem.Public()
+	}
+	return result
+}
+
+// QueueStatus describes a matchmaking queue
+type QueueStatus struct {
+	Difficulty  string `json:"difficulty"`
+	Language    string `json:"language"`
+	QueueSize   int    `json:"queue_size"`
+	LongestWait int    `json:"longest_wait_seconds"`
+
+	// Median wait of the queue's recent matches, nil before any
+	EstimatedWait *int `json:"estimated_wait_seconds"`
+}
+
+// GetQueueStatus returns the number of users waiting in a queue, how long
+// they have waited and how long a new player can expect to wait
+func (s *MatchService) GetQueueStatus(ctx context.Context, difficulty, language string) (*QueueStatus, error) {
+	key := queueKey(difficulty, language)
+
+	pipe := s.redis.Pipeline()
+	size := pipe.ZCard(ctx, key)
+	oldest := pipe.ZRangeWithScores(ctx, key, 0, 0)
+	waits := pipe.LRange(ctx, waitsKey(difficulty, language), 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	status := &QueueStatus{
+		Difficulty: difficulty,
+		Language:   language,
+		QueueSize:  int(size.Val()),
+	}
+	if entries := oldest.Val(); len(entries) > 0 {
+		status.LongestWait = int(time.Since(time.UnixMilli(int64(entries[0].Score))).Seconds())
+	}
+
+	var recent []int
+	for _, value := range waits.Val() {
+		var wait int
+		if _, err := fmt.Sscan(value, &wait); err == nil {
+			recent = append(recent, wait)
+		}
+	}
+	if len(recent) > 0 {
+		sort.Ints(recent)
+		estimate := recent[len(recent)/2] / 1000
+		status.EstimatedWait = &estimate
+	}
+	return status, nil
+}
+
+// RemoveFromQueue removes a user from the matchmaking queue
+func (s *MatchService) RemoveFromQueue(ctx context.Context, userID uuid.UUID, difficulty, language string) error {
+	return dequeueScript.Run(ctx, s.redis,
+		[]string{queueKey(difficulty, language), queuedUsersKey, queuedRatingsKey},
+		userID.String(),
+	).Err()
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRelaxAfter(t *testing.T) {
+	matchmaking := Matchmaking{RelaxDifficultyAfter: time.Minute, RelaxLanguageAfter: 2 * time.Minute}
+
+	tests := []struct {
+		name                 string
+		matchmaking          Matchmaking
+		difficulty, language string
+		after                time.Duration
+		ok                   bool
+	}{
+		{"same queue", matchmaking, "easy", "go", 0, true},
+		{"other difficulty", matchmaking, "hard", "go", time.Minute, true},
+		{"other language", matchmaking, "easy", "python", 2 * time.Minute, true},
+		{"both differ", matchmaking, "hard", "python", 2 * time.Minute, true},
+		{"difficulty never relaxed", Matchmaking{RelaxLanguageAfter: time.Minute}, "hard", "go", 0, false},
+		{"language never relaxed", DefaultMatchmaking, "easy", "python", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, ok := tt.matchmaking.relaxAfter("easy", "go", tt.difficulty, tt.language)
+			if after != tt.after || ok != tt.ok {
+				t.Errorf("relaxAfter = %v, %v, want %v, %v", after, ok, tt.after, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParsePairedValue(t *testing.T) {
+	now := time.Now()
+	matchID := uuid.New()
+
+	if got, ok := parsePairedValue(pairedValue(matchID, now.Add(time.Minute)), now); !ok || got != matchID {
+		t.Errorf("parsePairedValue = %s, %v, want %s, true", got, ok, matchID)
+	}
+	if _, ok := parsePairedValue(pairedValue(matchID, now.Add(-time.Minute)), now); ok {
+		t.Error("parsePairedValue accepted an expired match")
+	}
+	for _, value := range []string{"", "queue:easy:go", "match:nope:1", "match:" + matchID.String()} {
+		if _, ok := parsePairedValue(value, now); ok {
+			t.Errorf("parsePairedValue(%q) accepted an invalid value", value)
+		}
+	}
+}
+
+// matchmakingRedis returns the Redis in TEST_REDIS_URL, its database is
+// flushed
+func matchmakingRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set")
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("parse TEST_REDIS_URL: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() { client.Close() })
+	if err := client.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("flush redis: %v", err)
+	}
+	return client
+}
+
+func TestPairedUserIsNotQueued(t *testing.T) {
+	ctx := context.Background()
+	service := NewMatchService(matchmakingRedis(t))
+
+	first := &MatchRequest{UserID: uuid.New(), Difficulty: "easy", Language: "go"}
+	second := &MatchRequest{UserID: uuid.New(), Difficulty: "easy", Language: "go"}
+	for _, req := range []*MatchRequest{first, second} {
+		if queued, err := service.queue(ctx, req, 1500, time.Now()); err != nil || !queued {
+			t.Fatalf("queue: %v, %v, want queued", queued, err)
+		}
+	}
+
+	matchID := uuid.New()
+	paired, err := service.pair(ctx, second, matchID)
+	if err != nil || paired == nil || paired.opponentID != first.UserID {
+		t.Fatalf("pair = %+v, %v, want the first user", paired, err)
+	}
+
+	// Until the first user picks up their match they cannot queue again
+	if queued, err := service.queue(ctx, first, 1500, time.Now()); err != nil || queued {
+		t.Fatalf("queue of a paired user: %v, %v, want not queued", queued, err)
+	}
+	got, value, err := service.pairedMatch(ctx, first.UserID)
+	if err != nil || got != matchID {
+		t.Fatalf("pairedMatch = %s, %v, want %s", got, err, matchID)
+	}
+	if err := service.forgetPaired(ctx, first.UserID, value); err != nil {
+		t.Fatalf("forgetPaired: %v", err)
+	}
+	if got, _, err := service.pairedMatch(ctx, first.UserID); err != nil || got != uuid.Nil {
+		t.Fatalf("pairedMatch after forgetting = %s, %v, want none", got, err)
+	}
+	if queued, err := service.queue(ctx, first, 1500, time.Now()); err != nil || !queued {
+		t.Fatalf("queue after pickup: %v, %v, want queued", queued, err)
+	}
+}
+
+func TestQueueKeepsJoinTime(t *testing.T) {
+	ctx := context.Background()
+	service := NewMatchService(matchmakingRedis(t))
+	req := &MatchRequest{UserID: uuid.New(), Difficulty: "easy", Language: "go"}
+	joined := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+
+	// A requeued user keeps their place, queueing again does not move them
+	for _, at := range []time.Time{joined, time.Now()} {
+		if _, err := service.queue(ctx, req, 1500, at); err != nil {
+			t.Fatalf("queue: %v", err)
+		}
+		score, err := service.redis.ZScore(ctx, queueKey("easy", "go"), req.UserID.String()).Result()
+		if err != nil {
+			t.Fatalf("read join time: %v", err)
+		}
+		if got := time.UnixMilli(int64(score)); !got.Equal(joined) {
+			t.Errorf("user joined at %v, want %v", got, joined)
+		}
+	}
+}
+
+// TestPairedMatchWaitsForTheMatch needs TEST_REDIS_URL and a Postgres
+// database in TEST_DATABASE_URL, its users, problems and matches are
+// emptied
+func TestPairedMatchWaitsForTheMatch(t *testing.T) {
+	ctx := context.Background()
+	client := matchmakingRedis(t)
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := database.Initialize(url)
+	if err != nil {
+		t.Fatalf("initialize database: %v", err)
+	}
+	if err := db.Exec("TRUNCATE users, problems, matches CASCADE").Error; err != nil {
+		t.Fatalf("empty tables: %v", err)
+	}
+
+	service := NewMatchService(client)
+	service.SetDB(db)
+
+	var users []database.User
+	for _, name := range []string{"first", "second"} {
+		user := database.User{Username: name, Email: name + "@example.com", Password: "x"}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, user)
+	}
+	problem := database.Problem{Title: "Echo", Description: "Echo", Difficulty: "easy", Language: "go", TestCases: "[]"}
+	if err := db.Create(&problem).Error; err != nil {
+		t.Fatalf("create problem: %v", err)
+	}
+
+	first := &MatchRequest{UserID: users[0].ID, Difficulty: "easy", Language: "go"}
+	second := &MatchRequest{UserID: users[1].ID, Difficulty: "easy", Language: "go"}
+	for _, req := range []*MatchRequest{first, second} {
+		if _, err := service.queue(ctx, req, 1500, time.Now()); err != nil {
+			t.Fatalf("queue: %v", err)
+		}
+	}
+	matchID := uuid.New()
+	if paired, err := service.pair(ctx, second, matchID); err != nil || paired == nil {
+		t.Fatalf("pair = %+v, %v, want a pairing", paired, err)
+	}
+
+	// The opponent polls before their match is created
+	if result, err := service.PairedMatch(ctx, first.UserID); err != nil || result != nil {
+		t.Fatalf("PairedMatch before the match exists = %+v, %v, want nothing yet", result, err)
+	}
+	if queued, err := service.queue(ctx, first, 1500, time.Now()); err != nil || queued {
+		t.Fatalf("queue while the match is created: %v, %v, want not queued", queued, err)
+	}
+
+	match := &database.Match{
+		ID:         matchID,
+		Player1ID:  first.UserID,
+		Player2ID:  second.UserID,
+		ProblemID:  problem.ID,
+		Status:     MatchMatched,
+		Difficulty: "easy",
+		Language:   "go",
+	}
+	if err := db.Create(match).Error; err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+
+	result, err := service.PairedMatch(ctx, first.UserID)
+	if err != nil || result == nil || result.MatchID != matchID {
+		t.Fatalf("PairedMatch = %+v, %v, want match %s", result, err, matchID)
+	}
+	if result, err := service.PairedMatch(ctx, first.UserID); err != nil || result != nil {
+		t.Fatalf("second PairedMatch = %+v, %v, want nothing", result, err)
+	}
+}
+
+// TestMatchmakingConcurrency races users who queue, change queues, leave
+// and look for an opponent, and checks each ends up in exactly one match
+func TestMatchmakingConcurrency(t *testing.T) {
+	ctx := context.Background()
+	service := NewMatchService(matchmakingRedis(t))
+
+	const users = 40
+	var mu sync.Mutex
+	players := map[uuid.UUID][]uuid.UUID{} // match to players
+	matches := map[uuid.UUID][]uuid.UUID{} // player to matches
+	record := func(matchID, playerID uuid.UUID) {
+		mu.Lock()
+		defer mu.Unlock()
+		players[matchID] = append(players[matchID], playerID)
+		matches[playerID] = append(matches[playerID], matchID)
+	}
+
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(20 * time.Second)
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			random := rand.New(rand.NewSource(seed))
+			userID := uuid.New()
+
+			for time.Now().Before(deadline) {
+				// Requests of a user are sequential, as when polling the
+				// queue endpoint
+				if matchID, value, err := service.pairedMatch(ctx, userID); err != nil {
+					t.Errorf("pairedMatch: %v", err)
+					return
+				} else if matchID != uuid.Nil {
+					if err := service.forgetPaired(ctx, userID, value); err != nil {
+						t.Errorf("forgetPaired: %v", err)
+					}
+					record(matchID, userID)
+					return
+				}
+
+				req := &MatchRequest{UserID: userID, Difficulty: []string{"easy", "medium"}[random.Intn(2)], Language: "go"}
+				if _, err := service.queue(ctx, req, 1500, time.Now()); err != nil {
+					t.Errorf("queue: %v", err)
+					return
+				}
+				if random.Intn(4) == 0 {
+					if err := service.RemoveFromQueue(ctx, userID, req.Difficulty, req.Language); err != nil {
+						t.Errorf("RemoveFromQueue: %v", err)
+						return
+					}
+					continue
+				}
+
+				matchID := uuid.New()
+				paired, err := service.pair(ctx, req, matchID)
+				if err != nil {
+					t.Errorf("pair: %v", err)
+					return
+				}
+				if paired != nil {
+					// The opponent records the match when picking it up
+					record(matchID, userID)
+					return
+				}
+				time.Sleep(time.Duration(random.Intn(5)) * time.Millisecond)
+			}
+			t.Errorf("user %s was never matched", userID)
+		}(int64(i))
+	}
+	wg.Wait()
+
+	for matchID, ids := range players {
+		if len(ids) != 2 || ids[0] == ids[1] {
+			t.Errorf("match %s has players %v, want two", matchID, ids)
+		}
+	}
+	for playerID, ids := range matches {
+		if len(ids) != 1 {
+			t.Errorf("user %s is in %d matches, want 1", playerID, len(ids))
+		}
+	}
+	if len(matches) != users {
+		t.Errorf("%d users were matched, want %d", len(matches), users)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rating systems selectable in the configuration
+const (
+	RatingGlicko2 = "glicko2"
+	RatingElo     = "elo"
+)
+
+// Starting values of a new player's rating
+const (
+	InitialRating     = 1200
+	InitialDeviation  = 350
+	InitialVolatility = 0.06
+)
+
+// PlayerRating is a player's skill estimate. Elo only uses Rating.
+type PlayerRating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`  // Glicko rating deviation, the uncertainty of Rating
+	Volatility float64 `json:"volatility"` // Glicko-2 expected fluctuation of Rating
+}
+
+// RatingSystem updates a player's rating after a game against an opponent.
+// score is 1 for a win, 0.5 for a draw and 0 for a loss.
+type RatingSystem interface {
+	Rate(player, opponent PlayerRating, score float64) PlayerRating
+}
+
+// NewRatingSystem returns the named rating system, eloK is the K-factor
+// used by Elo
+func NewRatingSystem(name string, eloK int) (RatingSystem, error) {
+	switch name {
+	case RatingGlicko2, "":
+		return Glicko2{Tau: 0.5}, nil
+	case RatingElo:
+		if eloK <= 0 {
+			return nil, fmt.Errorf("elo K-factor must be positive, got %d", eloK)
+		}
+		return Elo{K: float64(eloK)}, nil
+	}
+	return nil, fmt.Errorf("unknown rating system %q", name)
+}
+
+// Elo moves a rating by K times the difference between the game's score
+// and its expected score
+type Elo struct {
+	K float64
+}
+
+func (e Elo) Rate(player, opponent PlayerRating, score float64) PlayerRating {
+	expected := 1 / (1 + math.Pow(10, (opponent.Rating-player.Rating)/400))
+	player.Rating += e.K * (score - expected)
+	return player
+}
+
+// glicko2Scale converts between the Glicko and Glicko-2 scales
+const glicko2Scale = 173.7178
+
+// Deviation bounds: new players start at the maximum, and the minimum
+// keeps ratings of regular players from freezing
+const (
+	minDeviation = 30
+	maxDeviation = InitialDeviation
+)
+
+// Glicko2 implements Mark Glickman's Glicko-2 system with every match as
+// its own rating period. Tau constrains how fast volatility changes.
+type Glicko2 struct {
+	Tau float64
+}
+
+func (g Glicko2) Rate(player, opponent PlayerRating, score float64) PlayerRating {
+	player = withDefaults(player)
+	opponent = withDefaults(opponent)
+
+	// Step 2: convert to the Glicko-2 scale
+	mu := (player.Rating - 1500) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	muJ := (opponent.Rating - 1500) / glicko2Scale
+	phiJ := opponent.Deviation / glicko2Scale
+
+	// Steps 3 and 4: estimated variance and improvement
+	gJ := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+	expected := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+	v := 1 / (gJ * gJ * expected * (1 - expected))
+	delta := v * gJ * (score - expected)
+
+	// Step 5: new volatility
+	sigma := g.volatility(phi, v, delta, player.Volatility)
+
+	// Steps 6 and 7: new deviation and rating
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*gJ*(score-expected)
+
+	// Step 8: back to the Glicko scale
+	return PlayerRating{
+		Rating:     newMu*glicko2Scale + 1500,
+		Deviation:  math.Min(math.Max(newPhi*glicko2Scale, minDeviation), maxDeviation),
+		Volatility: sigma,
+	}
+}
+
+// volatility solves for the new volatility with the Illinois algorithm
+func (g Glicko2) volatility(phi, v, delta, sigma float64) float64 {
+	const epsilon = 0.000001
+
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// withDefaults fills in the deviation and volatility of players rated
+// before they were tracked
+func withDefaults(rating PlayerRating) PlayerRating {
+	if rating.Deviation <= 0 {
+		rating.Deviation = InitialDeviation
+	}
+	if rating.Volatility <= 0 {
+		rating.Volatility = InitialVolatility
+	}
+	return rating
+}
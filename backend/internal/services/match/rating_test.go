@@ -0,0 +1,95 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEloRate(t *testing.T) {
+	elo := Elo{K: 32}
+
+	tests := []struct {
+		name             string
+		player, opponent float64
+		score            float64
+		want             float64
+	}{
+		{"even win", 1200, 1200, 1, 1216},
+		{"even draw", 1200, 1200, 0.5, 1200},
+		{"even loss", 1200, 1200, 0, 1184},
+		{"favourite wins", 1400, 1200, 1, 1407.6880},
+		{"underdog wins", 1200, 1400, 1, 1224.3120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := elo.Rate(PlayerRating{Rating: tt.player}, PlayerRating{Rating: tt.opponent}, tt.score)
+			if math.Abs(got.Rating-tt.want) > 0.001 {
+				t.Errorf("Rate = %.4f, want %.4f", got.Rating, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlicko2Rate(t *testing.T) {
+	glicko := Glicko2{Tau: 0.5}
+
+	tests := []struct {
+		name             string
+		player, opponent PlayerRating
+		score            float64
+		want             PlayerRating
+	}{
+		// The player of Glickman's example against the first opponent
+		{"win", PlayerRating{1500, 200, 0.06}, PlayerRating{1400, 30, 0.06}, 1, PlayerRating{1563.5642, 175.4027, 0.059999}},
+		{"loss", PlayerRating{1500, 200, 0.06}, PlayerRating{1400, 30, 0.06}, 0, PlayerRating{1387.2576, 175.4027, 0.060001}},
+		{"new players draw", PlayerRating{1500, 350, 0.06}, PlayerRating{1500, 350, 0.06}, 0.5, PlayerRating{1500, 290.3190, 0.059999}},
+		{"untracked players", PlayerRating{Rating: 1200}, PlayerRating{Rating: 1200}, 1, PlayerRating{1362.3109, 290.3190, 0.060000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := glicko.Rate(tt.player, tt.opponent, tt.score)
+			if math.Abs(got.Rating-tt.want.Rating) > 0.001 ||
+				math.Abs(got.Deviation-tt.want.Deviation) > 0.001 ||
+				math.Abs(got.Volatility-tt.want.Volatility) > 0.000001 {
+				t.Errorf("Rate = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlicko2DeviationBounds(t *testing.T) {
+	glicko := Glicko2{Tau: 0.5}
+
+	// Settled players stop becoming more certain
+	settled := PlayerRating{Rating: 1500, Deviation: minDeviation, Volatility: 0.001}
+	if got := glicko.Rate(settled, settled, 1); got.Deviation != minDeviation {
+		t.Errorf("deviation of a settled player = %.4f, want %d", got.Deviation, minDeviation)
+	}
+
+	// An upset never makes a player less certain than a new one
+	unsure := PlayerRating{Rating: 1500, Deviation: maxDeviation, Volatility: 0.5}
+	if got := glicko.Rate(unsure, PlayerRating{Rating: 3000, Deviation: 30}, 1); got.Deviation > maxDeviation {
+		t.Errorf("deviation after an upset = %.4f, want at most %d", got.Deviation, maxDeviation)
+	}
+}
+
+func TestNewRatingSystem(t *testing.T) {
+	tests := []struct {
+		name    string
+		eloK    int
+		want    RatingSystem
+		wantErr bool
+	}{
+		{"", 0, Glicko2{Tau: 0.5}, false},
+		{RatingGlicko2, 0, Glicko2{Tau: 0.5}, false},
+		{RatingElo, 24, Elo{K: 24}, false},
+		{RatingElo, 0, nil, true},
+		{"trueskill", 32, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := NewRatingSystem(tt.name, tt.eloK)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NewRatingSystem(%q, %d) = %v, %v, want %v, error %v", tt.name, tt.eloK, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"coderoulette/internal/analytics"
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of similarity flags
+const (
+	FlagSameMatch  = "same_match"  // both players of a match
+	FlagCrossMatch = "cross_match" // players of different matches
+	FlagReference  = "reference"   // a player and the problem's reference solution
+)
+
+// Review states of similarity flags
+const (
+	FlagOpen      = "open"
+	FlagConfirmed = "confirmed"
+	FlagDismissed = "dismissed"
+)
+
+const (
+	// Programs with fewer fingerprints than this, after removing the
+	// starter code, are too short to tell copying from coincidence
+	minFingerprints = 8
+
+	// scanCandidates bounds how many earlier submissions a new submission is
+	// compared with, and scanProblemLimit how many a problem scan covers
+	scanCandidates   = 500
+	scanProblemLimit = 2000
+)
+
+var ErrFlagNotFound = errors.New("similarity flag not found")
+
+// PlagiarismService compares submissions to the same problem and flags the
+// pairs whose code is alike enough for a moderator to look at
+type PlagiarismService struct {
+	db        *gorm.DB
+	threshold float64 // similarity from which pairs are flagged
+}
+
+// SimilarityFlagData is a flagged pair of submissions. Codes are only
+// filled in when a single flag is requested.
+type SimilarityFlagData struct {
+	ID            uuid.UUID          `json:"id"`
+	ProblemID     uuid.UUID          `json:"problem_id"`
+	Kind          string             `json:"kind"`
+	Similarity    float64            `json:"similarity"` // from 0 to 1
+	SubmissionA   SimilarSubmission  `json:"submission_a"`
+	SubmissionB   *SimilarSubmission `json:"submission_b,omitempty"`   // nil for the reference solution
+	ReferenceCode string             `json:"reference_code,omitempty"` // for reference flags
+	Status        string             `json:"status"`
+	Note          string             `json:"note"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// SimilarSubmission is one side of a flagged pair
+type SimilarSubmission struct {
+	ID        uuid.UUID `json:"id"`
+	MatchID   uuid.UUID `json:"match_id"`
+	PlayerID  uuid.UUID `json:"player_id"`
+	Language  string    `json:"language"`
+	Code      string    `json:"code,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// fingerprintedSubmission is a submission with its starter code free fingerprint
+type fingerprintedSubmission struct {
+	submission  *database.Submission
+	fingerprint analytics.Fingerprint
+}
+
+func NewPlagiarismService(db *gorm.DB) *PlagiarismService {
+	return &PlagiarismService{db: db, threshold: 0.8}
+}
+
+// SetThreshold sets the similarity in percent from which pairs are flagged
+func (s *PlagiarismService) SetThreshold(percent int) {
+	if percent > 0 && percent <= 100 {
+		s.threshold = float64(percent) / 100
+	}
+}
+
+// ScanSubmission compares a submission with the latest submissions of other
+// players to the same problem in the same language, within its match and
+// across matches, and with the problem's reference solution. It returns the
+// flags raised or updated.
+func (s *PlagiarismService) ScanSubmission(id uuid.UUID) ([]*SimilarityFlagData, error) {
+	var submission database.Submission
+	if err := s.db.Preload("Match.Problem").First(&submission, "id = ?", id).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubmissionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	problem, err := newProblemData(&submission.Match.Problem)
+	if err != nil {
+		return nil, err
+	}
+
+	target := s.fingerprint(problem, &submission)
+	if target == nil {
+		return nil, nil
+	}
+
+	var others []database.Submission
+	err = s.db.Joins("JOIN matches ON matches.id = submissions.match_id").
+		Where("matches.problem_id = ? AND submissions.language = ? AND submissions.player_id <> ?",
+			problem.ID, submission.Language, submission.PlayerID).
+		Order("submissions.created_at DESC").
+		Limit(scanCandidates).
+		Find(&others).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*fingerprintedSubmission
+	for i := range others {
+		if candidate := s.fingerprint(problem, &others[i]); candidate != nil {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return s.flag(problem, target, candidates)
+}
+
+// ScanProblem compares every pair of recent submissions to a problem by
+// different players and returns the number of flags raised or updated
+func (s *PlagiarismService) ScanProblem(problemID uuid.UUID) (int, error) {
+	var problem database.Problem
+	if err := s.db.First(&problem, "id = ?", problemID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrProblemNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	data, err := newProblemData(&problem)
+	if err != nil {
+		return 0, err
+	}
+
+	var submissions []database.Submission
+	err = s.db.Joins("JOIN matches ON matches.id = submissions.match_id").
+		Where("matches.problem_id = ?", problemID).
+		Order("submissions.created_at DESC").
+		Limit(scanProblemLimit).
+		Find(&submissions).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var scanned []*fingerprintedSubmission
+	for i := range submissions {
+		if item := s.fingerprint(data, &submissions[i]); item != nil {
+			scanned = append(scanned, item)
+		}
+	}
+
+	flagged := 0
+	for i, target := range scanned {
+		var candidates []*fingerprintedSubmission
+		for _, other := range scanned[i+1:] {
+			if other.submission.Language == target.submission.Language && other.submission.PlayerID != target.submission.PlayerID {
+				candidates = append(candidates, other)
+			}
+		}
+		flags, err := s.flag(data, target, candidates)
+		if err != nil {
+			return flagged, err
+		}
+		flagged += len(flags)
+	}
+	return flagged, nil
+}
+
+// fingerprint returns a submission's fingerprint without the fingerprints of
+// the starter code, or nil if too little of it is the player's own
+func (s *PlagiarismService) fingerprint(problem *ProblemData, submission *database.Submission) *fingerprintedSubmission {
+	fingerprint := analytics.Fingerprints(submission.Language, submission.Code)
+	if starter := problem.StarterCode[submission.Language]; starter != "" {
+		fingerprint = fingerprint.Without(analytics.Fingerprints(submission.Language, starter))
+	}
+	if len(fingerprint) < minFingerprints {
+		return nil
+	}
+	return &fingerprintedSubmission{submission: submission, fingerprint: fingerprint}
+}
+
+// flag records the target's most similar submission of each other player
+// and its similarity with the reference solution when above the threshold
+func (s *PlagiarismService) flag(problem *ProblemData, target *fingerprintedSubmission, candidates []*fingerprintedSubmission) ([]*SimilarityFlagData, error) {
+	// One flag per pair of players is enough to review them
+	best := make(map[uuid.UUID]*database.SimilarityFlag)
+	for _, candidate := range candidates {
+		similarity := analytics.Similarity(target.fingerprint, candidate.fingerprint)
+		if similarity < s.threshold {
+			continue
+		}
+		player := candidate.submission.PlayerID
+		if flag, ok := best[player]; ok && flag.Similarity >= similarity {
+			continue
+		}
+
+		kind := FlagCrossMatch
+		if candidate.submission.MatchID == target.submission.MatchID {
+			kind = FlagSameMatch
+		}
+		best[player] = &database.SimilarityFlag{
+			ProblemID:     problem.ID,
+			SubmissionAID: target.submission.ID,
+			SubmissionBID: &candidate.submission.ID,
+			PlayerAID:     target.submission.PlayerID,
+			PlayerBID:     &player,
+			Kind:          kind,
+			Similarity:    similarity,
+		}
+	}
+
+	flags := make([]*database.SimilarityFlag, 0, len(best)+1)
+	for _, flag := range best {
+		flags = append(flags, flag)
+	}
+
+	if problem.Solution != "" && problem.Language == target.submission.Language {
+		reference := s.fingerprint(problem, &database.Submission{Language: problem.Language, Code: problem.Solution})
+		if reference != nil {
+			if similarity := analytics.Similarity(target.fingerprint, reference.fingerprint); similarity >= s.threshold {
+				flags = append(flags, &database.SimilarityFlag{
+					ProblemID:     problem.ID,
+					SubmissionAID: target.submission.ID,
+					PlayerAID:     target.submission.PlayerID,
+					Kind:          FlagReference,
+					Similarity:    similarity,
+				})
+			}
+		}
+	}
+
+	sides := map[uuid.UUID]*database.Submission{target.submission.ID: target.submission}
+	for _, candidate := range candidates {
+		sides[candidate.submission.ID] = candidate.submission
+	}
+
+	result := make([]*SimilarityFlagData, 0, len(flags))
+	for _, flag := range flags {
+		stored, err := s.saveFlag(flag)
+		if err != nil {
+			return nil, err
+		}
+		// A stored flag may have the pair the other way round
+		stored.SubmissionA = *sides[stored.SubmissionAID]
+		if stored.SubmissionBID != nil {
+			stored.SubmissionB = sides[*stored.SubmissionBID]
+		}
+		result = append(result, newSimilarityFlagData(stored, false))
+	}
+	return result, nil
+}
+
+// saveFlag stores a new flag or updates the similarity of the existing flag
+// for the same pair, keeping a moderator's decision on it, and returns the
+// stored flag
+func (s *PlagiarismService) saveFlag(flag *database.SimilarityFlag) (*database.SimilarityFlag, error) {
+	query := s.db.Model(&database.SimilarityFlag{})
+	if flag.SubmissionBID == nil {
+		query = query.Where("submission_a_id = ? AND submission_b_id IS NULL", flag.SubmissionAID)
+	} else {
+		query = query.Where("(submission_a_id = ? AND submission_b_id = ?) OR (submission_a_id = ? AND submission_b_id = ?)",
+			flag.SubmissionAID, *flag.SubmissionBID, *flag.SubmissionBID, flag.SubmissionAID)
+	}
+
+	var existing database.SimilarityFlag
+	err := query.First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		flag.Status = FlagOpen
+		return flag, s.db.Create(flag).Error
+	} else if err != nil {
+		return nil, err
+	}
+
+	if existing.Status != FlagOpen || existing.Similarity == flag.Similarity {
+		return &existing, nil
+	}
+	// Similarities move when the problem's starter code changes
+	existing.Similarity = flag.Similarity
+	return &existing, s.db.Model(&existing).Update("similarity", existing.Similarity).Error
+}
+
+// ListFlags returns flags with pagination, most similar first, optionally
+// filtered by status and problem
+func (s *PlagiarismService) ListFlags(page, limit int, status string, problemID *uuid.UUID) ([]*SimilarityFlagData, int64, error) {
+	var flags []database.SimilarityFlag
+	var count int64
+
+	query := s.db.Model(&database.SimilarityFlag{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if problemID != nil {
+		query = query.Where("problem_id = ?", *problemID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("SubmissionA").Preload("SubmissionB").
+		Order("similarity DESC, created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&flags).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*SimilarityFlagData, len(flags))
+	for i := range flags {
+		result[i] = newSimilarityFlagData(&flags[i], false)
+	}
+	return result, count, nil
+}
+
+// GetFlag returns a flag with the code of both sides
+func (s *PlagiarismService) GetFlag(id uuid.UUID) (*SimilarityFlagData, error) {
+	var flag database.SimilarityFlag
+	if err := s.db.Preload("SubmissionA").Preload("SubmissionB").First(&flag, "id = ?", id).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFlagNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	data := newSimilarityFlagData(&flag, true)
+	if flag.Kind == FlagReference {
+		var problem database.Problem
+		if err := s.db.Select("solution").First(&problem, "id = ?", flag.ProblemID).Error; err != nil {
+			return nil, err
+		}
+		data.ReferenceCode = problem.Solution
+	}
+	return data, nil
+}
+
+// ReviewFlag records a moderator's decision on a flag
+func (s *PlagiarismService) ReviewFlag(id uuid.UUID, status, note string) (*SimilarityFlagData, error) {
+	if status != FlagOpen && status != FlagConfirmed && status != FlagDismissed {
+		return nil, fmt.Errorf("%w: unknown flag status %q", ErrInvalidInput, status)
+	}
+
+	result := s.db.Model(&database.SimilarityFlag{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": status,
+		"note":   note,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrFlagNotFound
+	}
+	return s.GetFlag(id)
+}
+
+func newSimilarityFlagData(flag *database.SimilarityFlag, withCode bool) *SimilarityFlagData {
+	data := &SimilarityFlagData{
+		ID:          flag.ID,
+		ProblemID:   flag.ProblemID,
+		Kind:        flag.Kind,
+		Similarity:  flag.Similarity,
+		SubmissionA: similarSubmission(flag.SubmissionAID, flag.PlayerAID, &flag.SubmissionA, withCode),
+		Status:      flag.Status,
+		Note:        flag.Note,
+		CreatedAt:   flag.CreatedAt,
+		UpdatedAt:   flag.UpdatedAt,
+	}
+	if flag.SubmissionBID != nil {
+		submission := flag.SubmissionB
+		if submission == nil {
+			submission = &database.Submission{}
+		}
+		side := similarSubmission(*flag.SubmissionBID, *flag.PlayerBID, submission, withCode)
+		data.SubmissionB = &side
+	}
+	return data
+}
+
+// similarSubmission describes one side of a flag from its IDs and, when
+// loaded, the submission itself
+func similarSubmission(id, playerID uuid.UUID, submission *database.Submission, withCode bool) SimilarSubmission {
+	side := SimilarSubmission{
+		ID:        id,
+		MatchID:   submission.MatchID,
+		PlayerID:  playerID,
+		Language:  submission.Language,
+		CreatedAt: submission.CreatedAt,
+	}
+	if withCode {
+		side.Code = submission.Code
+	}
+	return side
+}
@@ -0,0 +1,55 @@
+package services
+
+import "fmt"
+
+// Output comparison modes for Checker.Mode
+const (
+	CompareExact     = "exact"     // identical after trimming surrounding whitespace
+	CompareTokens    = "tokens"    // identical whitespace separated tokens
+	CompareFloat     = "float"     // numbers may differ by AbsEps or RelEps
+	CompareUnordered = "unordered" // same lines in any order
+	CompareCustom    = "custom"    // decided by a checker program
+)
+
+// Default tolerance of the float mode when neither epsilon is set
+const defaultFloatEps = 1e-6
+
+// Checker configures how a problem's outputs are compared with the expected ones.
+//
+// A custom checker is a program in any registered language. It is run as
+// `<run command> input.txt expected.txt actual.txt` and prints AC or WA,
+// optionally followed by the percentage of the test's weight to award,
+// e.g. "WA 40". Anything after the first line is shown as a message.
+type Checker struct {
+	Mode     string  `json:"mode"`
+	AbsEps   float64 `json:"abs_eps,omitempty"`
+	RelEps   float64 `json:"rel_eps,omitempty"`
+	Code     string  `json:"code,omitempty"`     // source of a custom checker
+	Language string  `json:"language,omitempty"` // language of a custom checker
+}
+
+// Validate checks the mode and its settings
+func (c *Checker) Validate() error {
+	switch c.Mode {
+	case "", CompareExact, CompareTokens, CompareUnordered:
+	case CompareFloat:
+		if c.AbsEps < 0 || c.RelEps < 0 {
+			return fmt.Errorf("epsilons must not be negative")
+		}
+	case CompareCustom:
+		if c.Code == "" || c.Language == "" {
+			return fmt.Errorf("a custom checker needs code and language")
+		}
+	default:
+		return fmt.Errorf("unknown comparison mode %q", c.Mode)
+	}
+	return nil
+}
+
+// Epsilons returns the absolute and relative tolerance of the float mode
+func (c *Checker) Epsilons() (abs, rel float64) {
+	if c.AbsEps == 0 && c.RelEps == 0 {
+		return defaultFloatEps, defaultFloatEps
+	}
+	return c.AbsEps, c.RelEps
+}
@@ -0,0 +1,47 @@
+package services
+
+import "fmt"
+
+// Generator is a program that prints a test input for a seed. It is run as
+// `<run command> <seed>`; the expected output of each generated test comes
+// from the reference solution. Generators also feed stress testing.
+type Generator struct {
+	Name     string   `json:"name"`
+	Code     string   `json:"code,omitempty"`
+	Language string   `json:"language"`
+	Seeds    []string `json:"seeds"` // one test per seed
+
+	// Settings of the generated tests
+	Hidden bool   `json:"hidden"`
+	Weight int    `json:"weight,omitempty"`
+	Group  string `json:"group,omitempty"`
+}
+
+// ValidateGenerators checks that generators are unique and runnable
+func (p *ProblemData) ValidateGenerators() error {
+	seen := make(map[string]bool)
+	for _, generator := range p.Generators {
+		if generator.Name == "" || seen[generator.Name] {
+			return fmt.Errorf("generator names must be unique and not empty")
+		}
+		seen[generator.Name] = true
+
+		if generator.Code == "" || generator.Language == "" {
+			return fmt.Errorf("generator %s needs code and language", generator.Name)
+		}
+		for _, seed := range generator.Seeds {
+			if seed == "" {
+				return fmt.Errorf("generator %s has an empty seed", generator.Name)
+			}
+		}
+	}
+	if len(p.Generators) > 0 && p.Solution == "" && !p.IsInteractive() {
+		return fmt.Errorf("generated tests need a reference solution")
+	}
+	return nil
+}
+
+// generatedTest names the generator run that produced a test
+func generatedTest(generator, seed string) string {
+	return generator + "#" + seed
+}
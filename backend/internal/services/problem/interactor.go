@@ -0,0 +1,51 @@
+package services
+
+import "fmt"
+
+// Problem types
+const (
+	ProblemStandard    = "standard"    // the program reads the test input and its output is checked
+	ProblemInteractive = "interactive" // the program talks to an interactor
+)
+
+// Interactor is the judge side of an interactive problem, a program in any
+// registered language. It is run as `<run command> input.txt` where
+// input.txt holds the test input, with its stdout connected to the
+// player's stdin and the player's stdout to its stdin. It exits with 0 to
+// accept the interaction and 1 for a wrong answer; its stderr is shown as
+// the reason. Any other exit is a judge failure.
+type Interactor struct {
+	Code     string `json:"code,omitempty"`
+	Language string `json:"language"`
+}
+
+// Interaction verdicts, as interactor exit codes
+const (
+	interactorAccepted    = 0
+	interactorWrongAnswer = 1
+)
+
+// ValidateType checks that the problem type and its settings agree
+func (p *ProblemData) ValidateType() error {
+	switch p.Type {
+	case "", ProblemStandard:
+		if p.Interactor != nil {
+			return fmt.Errorf("only interactive problems have an interactor")
+		}
+	case ProblemInteractive:
+		if p.Interactor == nil || p.Interactor.Code == "" || p.Interactor.Language == "" {
+			return fmt.Errorf("interactive problems need interactor code and language")
+		}
+		if p.Signature != nil || p.Checker != nil {
+			return fmt.Errorf("interactive problems cannot have a signature or checker")
+		}
+	default:
+		return fmt.Errorf("unknown problem type %q", p.Type)
+	}
+	return nil
+}
+
+// IsInteractive reports whether the problem is judged by an interactor
+func (p *ProblemData) IsInteractive() bool {
+	return p.Type == ProblemInteractive
+}
@@ -2,15 +2,20 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
 	"time"
 
 	"coderoulette/internal/database"
+	"coderoulette/internal/sandbox"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var ErrProblemNotFound = errors.New("problem not found")
+
 type ProblemService struct {
 	db *gorm.DB
 }
@@ -18,6 +23,12 @@ type ProblemService struct {
 type TestCase struct {
 	Input  string `json:"input"`
 	Output string `json:"output"`
+	Hidden bool   `json:"hidden"`           // hidden tests are judged but never shown to players
+	Weight int    `json:"weight,omitempty"` // relative weight in the score, 0 counts as 1
+	Group  string `json:"group,omitempty"`
+
+	// Generator and seed the test came from, empty for hand-written tests
+	Generated string `json:"generated,omitempty"`
 }
 
 type ProblemData struct {
@@ -27,7 +38,37 @@ type ProblemData struct {
 	Difficulty  string     `json:"difficulty"`
 	Language    string     `json:"language"`
 	TestCases   []TestCase `json:"test_cases"`
-	Solution    string     `json:"solution"`
+	Solution    string     `json:"solution,omitempty"`
+	Limits      Limits     `json:"limits"`
+
+	// Set for function problems, see Signature
+	Signature   *Signature        `json:"signature,omitempty"`
+	StarterCode map[string]string `json:"starter_code,omitempty"` // by language, generated from the signature
+
+	// How outputs are compared, nil means exact
+	Checker *Checker `json:"checker,omitempty"`
+
+	Type       string      `json:"type"` // standard or interactive
+	Interactor *Interactor `json:"interactor,omitempty"`
+
+	Subtasks []Subtask `json:"subtasks,omitempty"`
+
+	// Solutions that must fail a test, run with the reference solution
+	// when the problem is saved
+	WrongSolutions   []Solution        `json:"wrong_solutions,omitempty"`
+	ValidationStatus string            `json:"validation_status"`
+	Validation       *ValidationReport `json:"validation,omitempty"`
+
+	// Programs producing test inputs, see Generator
+	Generators []Generator `json:"generators,omitempty"`
+}
+
+// Limits holds the sandbox limits of a problem, zero values mean the default
+type Limits struct {
+	TimeLimit    int `json:"time_limit"`    // CPU time in milliseconds
+	MemoryLimit  int `json:"memory_limit"`  // in megabytes
+	ProcessLimit int `json:"process_limit"` // processes and threads
+	OutputLimit  int `json:"output_limit"`  // in kilobytes
 }
 
 func NewProblemService(db *gorm.DB) *ProblemService {
@@ -36,9 +77,47 @@ func NewProblemService(db *gorm.DB) *ProblemService {
 
 // GetRandomProblem returns a random problem based on difficulty and language
 func (s *ProblemService) GetRandomProblem(difficulty, language string) (*ProblemData, error) {
-	var problem database.Problem
+	return randomProblem(s.db, difficulty, language)
+}
+
+// PickProblem returns a random problem based on difficulty and language,
+// preferring problems not in avoid, or ErrProblemNotFound if there is none
+func (s *ProblemService) PickProblem(difficulty, language string, avoid []uuid.UUID) (*ProblemData, error) {
+	if len(avoid) > 0 {
+		problem, err := randomProblem(s.db.Where("id NOT IN ?", avoid), difficulty, language)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return problem, err
+		}
+	}
+
+	problem, err := randomProblem(s.db, difficulty, language)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProblemNotFound
+	}
+	return problem, err
+}
+
+// HasPlayableProblem reports whether a match of a difficulty and language
+// can be given a problem
+func (s *ProblemService) HasPlayableProblem(difficulty, language string) (bool, error) {
+	var count int64
+	err := playableProblems(s.db, difficulty, language).Count(&count).Error
+	return count > 0, err
+}
+
+// playableProblems narrows query to problems matches of a difficulty and
+// language may be given, in a new session per use so counting does not
+// leak into a later select
+func playableProblems(db *gorm.DB, difficulty, language string) *gorm.DB {
+	return db.Model(&database.Problem{}).
+		Where("difficulty = ? AND language = ? AND validation_status <> ?", difficulty, language, ValidationFailed).
+		Session(&gorm.Session{})
+}
 
-	query := s.db.Where("difficulty = ? AND language = ?", difficulty, language)
+// randomProblem returns a random problem of query that is playable
+func randomProblem(db *gorm.DB, difficulty, language string) (*ProblemData, error) {
+	var problem database.Problem
+	query := playableProblems(db, difficulty, language)
 
 	// Get count for random selection
 	var count int64
@@ -59,21 +138,7 @@ func (s *ProblemService) GetRandomProblem(difficulty, language string) (*Problem
 		return nil, err
 	}
 
-	// Parse test cases
-	var testCases []TestCase
-	if err := json.Unmarshal([]byte(problem.TestCases), &testCases); err != nil {
-		return nil, err
-	}
-
-	return &ProblemData{
-		ID:          problem.ID,
-		Title:       problem.Title,
-		Description: problem.Description,
-		Difficulty:  problem.Difficulty,
-		Language:    problem.Language,
-		TestCases:   testCases,
-		Solution:    problem.Solution,
-	}, nil
+	return newProblemData(&problem)
 }
 
 // GetProblemByID returns a problem by its ID
@@ -83,32 +148,119 @@ func (s *ProblemService) GetProblemByID(id uuid.UUID) (*ProblemData, error) {
 		return nil, err
 	}
 
-	// Parse test cases
-	var testCases []TestCase
-	if err := json.Unmarshal([]byte(problem.TestCases), &testCases); err != nil {
-		return nil, err
-	}
-
-	return &ProblemData{
-		ID:          problem.ID,
-		Title:       problem.Title,
-		Description: problem.Description,
-		Difficulty:  problem.Difficulty,
-		Language:    problem.Language,
-		TestCases:   testCases,
-		Solution:    problem.Solution,
-	}, nil
+	return newProblemData(&problem)
 }
 
 // CreateProblem creates a new problem
 func (s *ProblemService) CreateProblem(data *ProblemData) error {
+	problem, err := problemModel(data)
+	if err != nil {
+		return err
+	}
+	return s.db.Create(problem).Error
+}
+
+// UpdateProblem replaces a problem's content and settings
+func (s *ProblemService) UpdateProblem(id uuid.UUID, data *ProblemData) error {
+	var existing database.Problem
+	if err := s.db.First(&existing, "id = ?", id).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrProblemNotFound
+	} else if err != nil {
+		return err
+	}
+
+	data.ID = id
+	problem, err := problemModel(data)
+	if err != nil {
+		return err
+	}
+	problem.CreatedAt = existing.CreatedAt
+	return s.db.Save(problem).Error
+}
+
+// problemModel validates a problem's settings and converts it for storage
+func problemModel(data *ProblemData) (*database.Problem, error) {
 	// Serialize test cases
 	testCasesJSON, err := json.Marshal(data.TestCases)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var signatureJSON []byte
+	if data.Signature != nil {
+		if err := data.ValidateSignature(); err != nil {
+			return nil, err
+		}
+		if signatureJSON, err = json.Marshal(data.Signature); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := data.ValidateType(); err != nil {
+		return nil, err
+	}
+	if data.Type == "" {
+		data.Type = ProblemStandard
+	}
+
+	subtasksJSON := []byte("[]")
+	if len(data.Subtasks) > 0 {
+		if err := data.ValidateSubtasks(); err != nil {
+			return nil, err
+		}
+		if subtasksJSON, err = json.Marshal(data.Subtasks); err != nil {
+			return nil, err
+		}
+	}
+
+	var interactorJSON []byte
+	if data.Interactor != nil {
+		if interactorJSON, err = json.Marshal(data.Interactor); err != nil {
+			return nil, err
+		}
 	}
 
-	problem := &database.Problem{
+	var checkerJSON []byte
+	if data.Checker != nil {
+		if err := data.Checker.Validate(); err != nil {
+			return nil, err
+		}
+		if checkerJSON, err = json.Marshal(data.Checker); err != nil {
+			return nil, err
+		}
+	}
+
+	var generatorsJSON []byte
+	if len(data.Generators) > 0 {
+		if err := data.ValidateGenerators(); err != nil {
+			return nil, err
+		}
+		if generatorsJSON, err = json.Marshal(data.Generators); err != nil {
+			return nil, err
+		}
+	}
+
+	var wrongSolutionsJSON []byte
+	if len(data.WrongSolutions) > 0 {
+		if err := data.ValidateWrongSolutions(); err != nil {
+			return nil, err
+		}
+		if wrongSolutionsJSON, err = json.Marshal(data.WrongSolutions); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.ValidationStatus == "" {
+		data.ValidationStatus = ValidationPending
+	}
+	var validationJSON []byte
+	if data.Validation != nil {
+		if validationJSON, err = json.Marshal(data.Validation); err != nil {
+			return nil, err
+		}
+	}
+
+	return &database.Problem{
 		ID:          data.ID,
 		Title:       data.Title,
 		Description: data.Description,
@@ -116,9 +268,26 @@ func (s *ProblemService) CreateProblem(data *ProblemData) error {
 		Language:    data.Language,
 		TestCases:   string(testCasesJSON),
 		Solution:    data.Solution,
-	}
 
-	return s.db.Create(problem).Error
+		TimeLimit:    data.Limits.TimeLimit,
+		MemoryLimit:  data.Limits.MemoryLimit,
+		ProcessLimit: data.Limits.ProcessLimit,
+		OutputLimit:  data.Limits.OutputLimit,
+
+		Signature: string(signatureJSON),
+		Checker:   string(checkerJSON),
+
+		Type:       data.Type,
+		Interactor: string(interactorJSON),
+
+		Subtasks: string(subtasksJSON),
+
+		WrongSolutions:   string(wrongSolutionsJSON),
+		ValidationStatus: data.ValidationStatus,
+		ValidationReport: string(validationJSON),
+
+		Generators: string(generatorsJSON),
+	}, nil
 }
 
 // GetProblems returns a list of problems with pagination
@@ -148,24 +317,178 @@ func (s *ProblemService) GetProblems(page, limit int, difficulty, language strin
 
 	// Convert to ProblemData
 	result := make([]*ProblemData, len(problems))
-	for i, problem := range problems {
-		var testCases []TestCase
-		json.Unmarshal([]byte(problem.TestCases), &testCases)
-
-		result[i] = &ProblemData{
-			ID:          problem.ID,
-			Title:       problem.Title,
-			Description: problem.Description,
-			Difficulty:  problem.Difficulty,
-			Language:    problem.Language,
-			TestCases:   testCases,
-			Solution:    problem.Solution,
+	for i := range problems {
+		data, err := newProblemData(&problems[i])
+		if err != nil {
+			return nil, 0, err
 		}
+		result[i] = data
 	}
 
 	return result, count, nil
 }
 
+// newProblemData converts a stored problem into its API representation
+func newProblemData(problem *database.Problem) (*ProblemData, error) {
+	// Parse test cases
+	var testCases []TestCase
+	if err := json.Unmarshal([]byte(problem.TestCases), &testCases); err != nil {
+		return nil, err
+	}
+
+	data := &ProblemData{
+		ID:          problem.ID,
+		Title:       problem.Title,
+		Description: problem.Description,
+		Difficulty:  problem.Difficulty,
+		Language:    problem.Language,
+		TestCases:   testCases,
+		Solution:    problem.Solution,
+		Limits:      problemLimits(problem),
+	}
+
+	if problem.Signature != "" {
+		var signature Signature
+		if err := json.Unmarshal([]byte(problem.Signature), &signature); err != nil {
+			return nil, err
+		}
+		data.Signature = &signature
+		data.StarterCode = StarterCode(&signature)
+	}
+
+	data.Type = problem.Type
+	if data.Type == "" {
+		data.Type = ProblemStandard
+	}
+	if problem.Interactor != "" {
+		var interactor Interactor
+		if err := json.Unmarshal([]byte(problem.Interactor), &interactor); err != nil {
+			return nil, err
+		}
+		data.Interactor = &interactor
+	}
+
+	if problem.Subtasks != "" {
+		if err := json.Unmarshal([]byte(problem.Subtasks), &data.Subtasks); err != nil {
+			return nil, err
+		}
+	}
+
+	if problem.Checker != "" {
+		var checker Checker
+		if err := json.Unmarshal([]byte(problem.Checker), &checker); err != nil {
+			return nil, err
+		}
+		data.Checker = &checker
+	}
+
+	if problem.Generators != "" {
+		if err := json.Unmarshal([]byte(problem.Generators), &data.Generators); err != nil {
+			return nil, err
+		}
+	}
+
+	if problem.WrongSolutions != "" {
+		if err := json.Unmarshal([]byte(problem.WrongSolutions), &data.WrongSolutions); err != nil {
+			return nil, err
+		}
+	}
+	data.ValidationStatus = problem.ValidationStatus
+	if data.ValidationStatus == "" {
+		data.ValidationStatus = ValidationPending
+	}
+	if problem.ValidationReport != "" {
+		var report ValidationReport
+		if err := json.Unmarshal([]byte(problem.ValidationReport), &report); err != nil {
+			return nil, err
+		}
+		data.Validation = &report
+	}
+
+	return data, nil
+}
+
+// ValidateSignature checks the signature of a function problem and that
+// every test input matches it
+func (p *ProblemData) ValidateSignature() error {
+	if p.Signature == nil {
+		return nil
+	}
+	if err := p.Signature.Validate(); err != nil {
+		return err
+	}
+	for i, testCase := range p.TestCases {
+		if _, err := p.Signature.EncodeArgs(testCase.Input); err != nil {
+			return fmt.Errorf("test %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Public returns a copy of the problem that is safe to show to players:
+// only sample tests, no solutions, no validation report and no checker,
+// interactor or generator source
+func (p *ProblemData) Public() *ProblemData {
+	public := *p
+	public.Solution = ""
+	public.WrongSolutions = nil
+	public.Validation = nil
+	public.Generators = nil
+	public.TestCases = SampleTestCases(p.TestCases)
+	if p.Checker != nil {
+		checker := *p.Checker
+		checker.Code = ""
+		public.Checker = &checker
+	}
+	if p.Interactor != nil {
+		interactor := *p.Interactor
+		interactor.Code = ""
+		public.Interactor = &interactor
+	}
+	return &public
+}
+
+// SampleTestCases returns the test cases that are visible to players
+func SampleTestCases(testCases []TestCase) []TestCase {
+	samples := []TestCase{}
+	for _, testCase := range testCases {
+		if !testCase.Hidden {
+			samples = append(samples, testCase)
+		}
+	}
+	return samples
+}
+
+// problemLimits extracts the sandbox limits stored on a problem
+func problemLimits(problem *database.Problem) Limits {
+	return Limits{
+		TimeLimit:    problem.TimeLimit,
+		MemoryLimit:  problem.MemoryLimit,
+		ProcessLimit: problem.ProcessLimit,
+		OutputLimit:  problem.OutputLimit,
+	}
+}
+
+// Sandbox converts the problem limits into sandbox limits, taking unset
+// values from base (usually the language's defaults)
+func (l Limits) Sandbox(base sandbox.Limits) sandbox.Limits {
+	limits := base
+	if l.TimeLimit > 0 {
+		limits.TimeLimit = time.Duration(l.TimeLimit) * time.Millisecond
+		limits.WallTimeLimit = 2*limits.TimeLimit + time.Second
+	}
+	if l.MemoryLimit > 0 {
+		limits.MemoryLimit = int64(l.MemoryLimit) << 20
+	}
+	if l.ProcessLimit > 0 {
+		limits.MaxProcesses = l.ProcessLimit
+	}
+	if l.OutputLimit > 0 {
+		limits.MaxOutputSize = int64(l.OutputLimit) << 10
+	}
+	return limits
+}
+
 // SeedProblems creates some sample problems for testing
 func (s *ProblemService) SeedProblems() error {
 	sampleProblems := []*ProblemData{
@@ -175,24 +498,34 @@ func (s *ProblemService) SeedProblems() error {
 			Description: "Given an array of integers nums and an integer target, return indices of the two numbers such that they add up to target.",
 			Difficulty:  "easy",
 			Language:    "go",
+			Signature: &Signature{
+				Function: "twoSum",
+				Params:   []Param{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+				Returns:  "int[]",
+			},
 			TestCases: []TestCase{
 				{Input: "[2,7,11,15], 9", Output: "[0,1]"},
-				{Input: "[3,2,4], 6", Output: "[1,2]"},
-				{Input: "[3,3], 6", Output: "[0,1]"},
+				{Input: "[3,2,4], 6", Output: "[1,2]", Hidden: true},
+				{Input: "[3,3], 6", Output: "[0,1]", Hidden: true},
 			},
 			Solution: "func twoSum(nums []int, target int) []int {\n    m := make(map[int]int)\n    for i, num := range nums {\n        if j, ok := m[target-num]; ok {\n            return []int{j, i}\n        }\n        m[num] = i\n    }\n    return nil\n}",
 		},
 		{
 			ID:          uuid.New(),
 			Title:       "Reverse String",
-			Description: "Write a function that reverses a string. The input string is given as an array of characters s.",
+			Description: "Write a function that reverses a string. The input string is given as an array of characters s, return the reversed array.",
 			Difficulty:  "easy",
 			Language:    "go",
+			Signature: &Signature{
+				Function: "reverseString",
+				Params:   []Param{{Name: "s", Type: "string[]"}},
+				Returns:  "string[]",
+			},
 			TestCases: []TestCase{
 				{Input: "[\"h\",\"e\",\"l\",\"l\",\"o\"]", Output: "[\"o\",\"l\",\"l\",\"e\",\"h\"]"},
-				{Input: "[\"H\",\"a\",\"n\",\"n\",\"a\",\"h\"]", Output: "[\"h\",\"a\",\"n\",\"n\",\"a\",\"H\"]"},
+				{Input: "[\"H\",\"a\",\"n\",\"n\",\"a\",\"h\"]", Output: "[\"h\",\"a\",\"n\",\"n\",\"a\",\"H\"]", Hidden: true},
 			},
-			Solution: "func reverseString(s []byte) {\n    left, right := 0, len(s)-1\n    for left < right {\n        s[left], s[right] = s[right], s[left]\n        left++\n        right--\n    }\n}",
+			Solution: "func reverseString(s []string) []string {\n    left, right := 0, len(s)-1\n    for left < right {\n        s[left], s[right] = s[right], s[left]\n        left++\n        right--\n    }\n    return s\n}",
 		},
 		{
 			ID:          uuid.New(),
@@ -200,11 +533,16 @@ func (s *ProblemService) SeedProblems() error {
 			Description: "Given a string s containing just the characters '(', ')', '{', '}', '[' and ']', determine if the input string is valid.",
 			Difficulty:  "medium",
 			Language:    "go",
+			Signature: &Signature{
+				Function: "isValid",
+				Params:   []Param{{Name: "s", Type: "string"}},
+				Returns:  "bool",
+			},
 			TestCases: []TestCase{
 				{Input: "\"()\"", Output: "true"},
-				{Input: "\"()[]{}\"", Output: "true"},
-				{Input: "\"(]\"", Output: "false"},
-				{Input: "\"([)]\"", Output: "false"},
+				{Input: "\"()[]{}\"", Output: "true", Hidden: true},
+				{Input: "\"(]\"", Output: "false", Hidden: true},
+				{Input: "\"([)]\"", Output: "false", Hidden: true},
 			},
 			Solution: "func isValid(s string) bool {\n    stack := []rune{}\n    pairs := map[rune]rune{\n        ')': '(',\n        '}': '{',\n        ']': '[',\n    }\n    \n    for _, char := range s {\n        if char == '(' || char == '{' || char == '[' {\n            stack = append(stack, char)\n        } else if len(stack) > 0 && stack[len(stack)-1] == pairs[char] {\n            stack = stack[:len(stack)-1]\n        } else {\n            return false\n        }\n    }\n    \n    return len(stack) == 0\n}",
 		},
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"coderoulette/internal/database"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunProblemDB returns a database that builds statements without
+// running them, recording the SQL of each query
+func dryRunProblemDB(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+
+	var statements []string
+	db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	return db, &statements
+}
+
+func TestPickProblemBuildsQueries(t *testing.T) {
+	db, statements := dryRunProblemDB(t)
+	service := NewProblemService(db)
+
+	// Nothing is returned in a dry run, so both attempts come up empty
+	_, err := service.PickProblem("easy", "go", []uuid.UUID{uuid.New()})
+	if !errors.Is(err, ErrProblemNotFound) {
+		t.Fatalf("PickProblem error = %v, want ErrProblemNotFound", err)
+	}
+
+	if len(*statements) != 2 {
+		t.Fatalf("ran %d queries, want 2: %q", len(*statements), *statements)
+	}
+	avoiding, fallback := (*statements)[0], (*statements)[1]
+	for _, sql := range *statements {
+		if !strings.Contains(sql, `FROM "problems"`) {
+			t.Errorf("query %q does not read problems", sql)
+		}
+	}
+	if !strings.Contains(avoiding, "NOT IN") {
+		t.Errorf("first query %q does not avoid recent problems", avoiding)
+	}
+	if strings.Contains(fallback, "NOT IN") {
+		t.Errorf("fallback query %q still avoids recent problems", fallback)
+	}
+}
+
+// TestPickProblem needs a Postgres database in TEST_DATABASE_URL, its
+// problems table is emptied
+func TestPickProblem(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := database.Initialize(url)
+	if err != nil {
+		t.Fatalf("initialize database: %v", err)
+	}
+	if err := db.Exec("TRUNCATE problems CASCADE").Error; err != nil {
+		t.Fatalf("empty problems: %v", err)
+	}
+
+	service := NewProblemService(db)
+	if _, err := service.PickProblem("easy", "go", nil); !errors.Is(err, ErrProblemNotFound) {
+		t.Fatalf("PickProblem without problems: error = %v, want ErrProblemNotFound", err)
+	}
+
+	seen := &ProblemData{Title: "Seen", Difficulty: "easy", Language: "go", ValidationStatus: ValidationPassed}
+	fresh := &ProblemData{Title: "Fresh", Difficulty: "easy", Language: "go", ValidationStatus: ValidationPassed}
+	invalid := &ProblemData{Title: "Invalid", Difficulty: "easy", Language: "go", ValidationStatus: ValidationFailed}
+	for _, problem := range []*ProblemData{seen, fresh, invalid} {
+		if err := service.CreateProblem(problem); err != nil {
+			t.Fatalf("create %s: %v", problem.Title, err)
+		}
+	}
+	ids := map[string]uuid.UUID{}
+	var problems []database.Problem
+	if err := db.Find(&problems).Error; err != nil {
+		t.Fatalf("list problems: %v", err)
+	}
+	for _, problem := range problems {
+		ids[problem.Title] = problem.ID
+	}
+
+	for i := 0; i < 10; i++ {
+		problem, err := service.PickProblem("easy", "go", []uuid.UUID{ids["Seen"]})
+		if err != nil {
+			t.Fatalf("PickProblem: %v", err)
+		}
+		if problem.ID != ids["Fresh"] {
+			t.Fatalf("PickProblem picked %q, want Fresh", problem.Title)
+		}
+	}
+
+	// Every playable problem was seen, so any of them will do
+	problem, err := service.PickProblem("easy", "go", []uuid.UUID{ids["Seen"], ids["Fresh"]})
+	if err != nil {
+		t.Fatalf("PickProblem when all were seen: %v", err)
+	}
+	if problem.ID == ids["Invalid"] {
+		t.Fatal("PickProblem picked an invalid problem")
+	}
+}
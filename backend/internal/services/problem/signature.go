@@ -0,0 +1,156 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Signature describes the function a function-style problem asks players
+// to implement. Test inputs are the JSON encoded arguments separated by
+// commas, e.g. `[2,7,11,15], 9`, and outputs the JSON encoded return value.
+type Signature struct {
+	Function string  `json:"function"`
+	Params   []Param `json:"params"`
+	Returns  string  `json:"returns"`
+}
+
+// Param is a named, typed function parameter
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ValueType is a signature type: one of int, long, double, bool or string,
+// optionally as an array (int[]) or a two dimensional array (int[][])
+type ValueType struct {
+	Base string
+	Dims int
+}
+
+var (
+	valueBases = map[string]bool{"int": true, "long": true, "double": true, "bool": true, "string": true}
+	identifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+// ParseValueType parses a type such as int or string[][]
+func ParseValueType(s string) (ValueType, error) {
+	base := strings.TrimSpace(s)
+	dims := 0
+	for strings.HasSuffix(base, "[]") {
+		base = strings.TrimSuffix(base, "[]")
+		dims++
+	}
+	if !valueBases[base] || dims > 2 {
+		return ValueType{}, fmt.Errorf("unsupported type %q", s)
+	}
+	return ValueType{Base: base, Dims: dims}, nil
+}
+
+// Elem returns the element type of an array type
+func (t ValueType) Elem() ValueType {
+	return ValueType{Base: t.Base, Dims: t.Dims - 1}
+}
+
+// Validate checks the function name, parameter names and all types
+func (sig *Signature) Validate() error {
+	if !identifier.MatchString(sig.Function) {
+		return fmt.Errorf("invalid function name %q", sig.Function)
+	}
+	if _, err := ParseValueType(sig.Returns); err != nil {
+		return fmt.Errorf("return type: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, param := range sig.Params {
+		if !identifier.MatchString(param.Name) || param.Name == sig.Function {
+			return fmt.Errorf("invalid parameter name %q", param.Name)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("duplicate parameter %q", param.Name)
+		}
+		seen[param.Name] = true
+
+		if _, err := ParseValueType(param.Type); err != nil {
+			return fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+	}
+	return nil
+}
+
+// EncodeArgs converts a test input of JSON arguments into the token stream
+// the generated drivers read on stdin. Every value is whitespace separated:
+// numbers and bools as literals, strings as their byte length followed by
+// one separator and the raw bytes, arrays as their length followed by the
+// elements.
+func (sig *Signature) EncodeArgs(input string) (string, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal([]byte("["+input+"]"), &args); err != nil {
+		return "", fmt.Errorf("arguments are not valid JSON: %w", err)
+	}
+	if len(args) != len(sig.Params) {
+		return "", fmt.Errorf("expected %d arguments, got %d", len(sig.Params), len(args))
+	}
+
+	var b strings.Builder
+	for i, param := range sig.Params {
+		valueType, err := ParseValueType(param.Type)
+		if err != nil {
+			return "", err
+		}
+		if err := encodeValue(&b, valueType, args[i]); err != nil {
+			return "", fmt.Errorf("argument %s: %w", param.Name, err)
+		}
+	}
+	return b.String(), nil
+}
+
+func encodeValue(b *strings.Builder, t ValueType, raw json.RawMessage) error {
+	if t.Dims > 0 {
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%d\n", len(items))
+		for _, item := range items {
+			if err := encodeValue(b, t.Elem(), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch t.Base {
+	case "int", "long":
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return err
+		}
+		if t.Base == "int" && (n < math.MinInt32 || n > math.MaxInt32) {
+			return fmt.Errorf("%d does not fit in an int", n)
+		}
+		fmt.Fprintf(b, "%d\n", n)
+	case "double":
+		var f float64
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return err
+		}
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, 64) + "\n")
+	case "bool":
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		b.WriteString(strconv.FormatBool(v) + "\n")
+	case "string":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%d %s\n", len(s), s)
+	}
+	return nil
+}
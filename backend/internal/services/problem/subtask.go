@@ -0,0 +1,48 @@
+package services
+
+import "fmt"
+
+// Subtask scoring policies
+const (
+	SubtaskAll = "all" // full points only if every test passes
+	SubtaskMin = "min" // points scaled by the lowest test score
+	SubtaskSum = "sum" // points scaled by the weighted average test score
+)
+
+// Subtask is a group of tests worth a number of points. Tests belong to the
+// subtask whose name matches their Group. When a problem has subtasks only
+// tests in a subtask count towards the score.
+type Subtask struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	Policy string `json:"policy"` // all (default), min or sum
+}
+
+// ValidateSubtasks checks that subtasks are unique, worth points and have tests
+func (p *ProblemData) ValidateSubtasks() error {
+	tests := make(map[string]int)
+	for _, testCase := range p.TestCases {
+		tests[testCase.Group]++
+	}
+
+	seen := make(map[string]bool)
+	for _, subtask := range p.Subtasks {
+		if subtask.Name == "" || seen[subtask.Name] {
+			return fmt.Errorf("subtask names must be unique and not empty")
+		}
+		seen[subtask.Name] = true
+
+		if subtask.Points <= 0 {
+			return fmt.Errorf("subtask %s: points must be positive", subtask.Name)
+		}
+		switch subtask.Policy {
+		case "", SubtaskAll, SubtaskMin, SubtaskSum:
+		default:
+			return fmt.Errorf("subtask %s: unknown policy %q", subtask.Name, subtask.Policy)
+		}
+		if tests[subtask.Name] == 0 {
+			return fmt.Errorf("subtask %s has no tests", subtask.Name)
+		}
+	}
+	return nil
+}
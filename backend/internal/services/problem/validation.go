@@ -0,0 +1,28 @@
+package services
+
+import "fmt"
+
+// Validation states of a problem
+const (
+	ValidationPending = "unvalidated" // no reference solution was run
+	ValidationPassed  = "valid"
+	ValidationFailed  = "invalid" // kept out of matches until fixed
+)
+
+// Solution is a program for a problem, used to validate it
+type Solution struct {
+	Name     string `json:"name"`
+	Code     string `json:"code"`
+	Language string `json:"language"`
+}
+
+// ValidateWrongSolutions checks that every known-wrong solution has code
+// and a language
+func (p *ProblemData) ValidateWrongSolutions() error {
+	for i, solution := range p.WrongSolutions {
+		if solution.Code == "" || solution.Language == "" {
+			return fmt.Errorf("wrong solution %d needs code and language", i+1)
+		}
+	}
+	return nil
+}
@@ -31,9 +31,18 @@ type PlayerStats struct {
 	FinalScore       int       `json:"final_score"`
 	BestScore        int       `json:"best_score"`
 	TotalSubmissions int       `json:"total_submissions"`
-	AverageRuntime   int       `json:"average_runtime"`
+	AverageRuntime   int       `json:"average_runtime"` // CPU time in milliseconds
+	AverageMemory    int       `json:"average_memory"`  // peak memory in kilobytes
+	PeakMemory       int       `json:"peak_memory"`     // in kilobytes
 	FirstSubmission  time.Time `json:"first_submission"`
 	LastSubmission   time.Time `json:"last_submission"`
+
+	// Code quality of the player's last analyzed submission
+	Quality *AnalysisSummary `json:"quality,omitempty"`
+
+	// Rating after the match, or the current one on the leaderboard
+	Rating      int `json:"rating,omitempty"`
+	RatingDelta int `json:"rating_delta"`
 }
 
 type ProblemSummary struct {
@@ -42,6 +51,8 @@ type ProblemSummary struct {
 	Difficulty    string    `json:"difficulty"`
 	Language      string    `json:"language"`
 	TestCaseCount int       `json:"test_case_count"`
+	HiddenCount   int       `json:"hidden_count"`
+	SubtaskCount  int       `json:"subtask_count"`
 }
 
 type SubmissionSummary struct {
@@ -49,8 +60,27 @@ type SubmissionSummary struct {
 	PlayerID  uuid.UUID `json:"player_id"`
 	Score     int       `json:"score"`
 	Runtime   int       `json:"runtime"`
+	Memory    int       `json:"memory"`
 	Status    string    `json:"status"`
+	Verdict   string    `json:"verdict"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Hidden tests are only reported as pass counts
+	HiddenPassed int `json:"hidden_passed"`
+	HiddenTotal  int `json:"hidden_total"`
+
+	Subtasks []SubtaskResult `json:"subtasks,omitempty"`
+
+	Analysis *AnalysisSummary `json:"analysis,omitempty"`
+}
+
+// AnalysisSummary condenses the code quality analysis of a submission
+type AnalysisSummary struct {
+	SubmissionID uuid.UUID      `json:"submission_id"`
+	Lines        int            `json:"lines"`
+	Complexity   int            `json:"complexity"`
+	Findings     int            `json:"findings"`
+	ByTool       map[string]int `json:"by_tool,omitempty"` // findings per analyzer
 }
 
 func NewReportService(db *gorm.DB) *ReportService {
@@ -75,22 +105,47 @@ func (s *ReportService) GenerateReport(matchID uuid.UUID) (*ReportData, error) {
 	player1Stats := s.calculatePlayerStats(match.Player1ID, submissions)
 	player2Stats := s.calculatePlayerStats(match.Player2ID, submissions)
 
+	// Rating changes are recorded when the match completes
+	var histories []database.RatingHistory
+	if err := s.db.Where("match_id = ?", matchID).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		stats := &player1Stats
+		if history.UserID == match.Player2ID {
+			stats = &player2Stats
+		}
+		stats.Rating = history.Rating
+		stats.RatingDelta = history.RatingDelta
+	}
+
 	// Create submission summaries
 	submissionSummaries := make([]SubmissionSummary, len(submissions))
 	for i, sub := range submissions {
+		hiddenPassed, hiddenTotal := hiddenPassCounts(sub.TestResults)
+		var subtasks []SubtaskResult
+		json.Unmarshal([]byte(sub.Subtasks), &subtasks)
 		submissionSummaries[i] = SubmissionSummary{
-			ID:        sub.ID,
-			PlayerID:  sub.PlayerID,
-			Score:     sub.Score,
-			Runtime:   sub.Runtime,
-			Status:    sub.Status,
-			CreatedAt: sub.CreatedAt,
+			ID:           sub.ID,
+			PlayerID:     sub.PlayerID,
+			Score:        sub.Score,
+			Runtime:      sub.Runtime,
+			Memory:       sub.Memory,
+			Status:       sub.Status,
+			Verdict:      sub.Verdict,
+			CreatedAt:    sub.CreatedAt,
+			HiddenPassed: hiddenPassed,
+			HiddenTotal:  hiddenTotal,
+			Subtasks:     subtasks,
+			Analysis:     summarizeAnalysis(&sub),
 		}
 	}
 
-	// Parse test cases to get count
+	// Parse test cases and subtasks to get counts
 	var testCases []TestCase
 	json.Unmarshal([]byte(match.Problem.TestCases), &testCases)
+	var problemSubtasks []Subtask
+	json.Unmarshal([]byte(match.Problem.Subtasks), &problemSubtasks)
 
 	report := &ReportData{
 		MatchID:  matchID,
@@ -104,6 +159,8 @@ func (s *ReportService) GenerateReport(matchID uuid.UUID) (*ReportData, error) {
 			Difficulty:    match.Problem.Difficulty,
 			Language:      match.Problem.Language,
 			TestCaseCount: len(testCases),
+			HiddenCount:   len(testCases) - len(SampleTestCases(testCases)),
+			SubtaskCount:  len(problemSubtasks),
 		},
 		Submissions: submissionSummaries,
 		CreatedAt:   match.CreatedAt,
@@ -145,16 +202,25 @@ func (s *ReportService) calculatePlayerStats(playerID uuid.UUID, submissions []d
 
 	bestScore := 0
 	totalRuntime := 0
+	totalMemory := 0
 	validSubmissions := 0
 
 	for _, sub := range playerSubmissions {
 		if sub.Score > bestScore {
 			bestScore = sub.Score
 		}
-		if sub.Runtime > 0 {
+		// Only submissions that ran tests have measurements
+		if sub.Status == "done" && sub.TestsDone > 0 {
 			totalRuntime += sub.Runtime
+			totalMemory += sub.Memory
 			validSubmissions++
 		}
+		if sub.Memory > playerStats.PeakMemory {
+			playerStats.PeakMemory = sub.Memory
+		}
+		if quality := summarizeAnalysis(&sub); quality != nil {
+			playerStats.Quality = quality
+		}
 	}
 
 	playerStats.BestScore = bestScore
@@ -162,11 +228,55 @@ func (s *ReportService) calculatePlayerStats(playerID uuid.UUID, submissions []d
 
 	if validSubmissions > 0 {
 		playerStats.AverageRuntime = totalRuntime / validSubmissions
+		playerStats.AverageMemory = totalMemory / validSubmissions
 	}
 
 	return playerStats
 }
 
+// hiddenPassCounts counts passed and total hidden tests in stored test results
+func hiddenPassCounts(testResultsJSON string) (int, int) {
+	var testResults []TestCaseResult
+	json.Unmarshal([]byte(testResultsJSON), &testResults)
+
+	passed, total := 0, 0
+	for _, result := range testResults {
+		if result.Hidden {
+			total++
+			if result.Passed {
+				passed++
+			}
+		}
+	}
+	return passed, total
+}
+
+// summarizeAnalysis counts the findings of a submission's analysis, nil if
+// it was not analyzed
+func summarizeAnalysis(submission *database.Submission) *AnalysisSummary {
+	if submission.Analysis == "" {
+		return nil
+	}
+	var analysis Analysis
+	if err := json.Unmarshal([]byte(submission.Analysis), &analysis); err != nil {
+		return nil
+	}
+
+	summary := &AnalysisSummary{
+		SubmissionID: submission.ID,
+		Lines:        analysis.Lines,
+		Complexity:   analysis.Complexity,
+		Findings:     len(analysis.Findings),
+	}
+	for _, finding := range analysis.Findings {
+		if summary.ByTool == nil {
+			summary.ByTool = make(map[string]int)
+		}
+		summary.ByTool[finding.Tool]++
+	}
+	return summary
+}
+
 // saveReport saves the report to database
 func (s *ReportService) saveReport(matchID uuid.UUID, report *ReportData) error {
 	reportJSON, err := json.Marshal(report)
@@ -245,8 +355,16 @@ func (s *ReportService) GetLeaderboard(limit int) ([]PlayerStats, error) {
 			ID:         user.ID,
 			Username:   user.Username,
 			FinalScore: user.Rating, // Using rating as final score for leaderboard
+			Rating:     user.Rating,
 		}
 	}
 
 	return result, nil
 }
+
+// GetRatingHistory returns a user's rating changes, most recent first
+func (s *ReportService) GetRatingHistory(userID uuid.UUID, limit int) ([]database.RatingHistory, error) {
+	var history []database.RatingHistory
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&history).Error
+	return history, err
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"coderoulette/internal/config"
 	"coderoulette/internal/database"
 	"coderoulette/internal/handlers"
+	"coderoulette/internal/sandbox"
 	"coderoulette/internal/services"
+	"coderoulette/pkg/limiter"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -34,14 +38,57 @@ func main() {
 		log.Fatal("Failed to connect to Redis:", err)
 	}
 
+	ratings, err := services.NewRatingSystem(cfg.RatingSystem, cfg.EloKFactor)
+	if err != nil {
+		log.Fatal("Invalid rating configuration:", err)
+	}
+
 	// Initialize services
+	problemService := services.NewProblemService(db)
 	matchService := services.NewMatchService(redisClient)
 	matchService.SetDB(db)
-	problemService := services.NewProblemService(db)
+	matchService.SetRatingSystem(ratings)
+	matchService.SetProblemService(problemService)
+	matchService.SetMatchmaking(services.Matchmaking{
+		RatingGap:            cfg.MatchRatingGap,
+		GapPerSecond:         cfg.MatchGapPerSecond,
+		MaxRatingGap:         cfg.MatchMaxRatingGap,
+		RelaxDifficultyAfter: time.Duration(cfg.MatchRelaxDifficulty) * time.Second,
+		RelaxLanguageAfter:   time.Duration(cfg.MatchRelaxLanguage) * time.Second,
+	})
+	matchService.SetTimers(services.MatchTimers{
+		ReadyTimeout: time.Duration(cfg.MatchReadyTimeout) * time.Second,
+		Countdown:    time.Duration(cfg.MatchCountdown) * time.Second,
+		Durations: map[string]time.Duration{
+			"easy":   time.Duration(cfg.MatchDurationEasy) * time.Second,
+			"medium": time.Duration(cfg.MatchDurationMedium) * time.Second,
+			"hard":   time.Duration(cfg.MatchDurationHard) * time.Second,
+		},
+	})
 	judgeService := services.NewJudgeService()
 	judgeService.SetDB(db)
+	judgeService.SetSandbox(sandbox.New(cfg.SandboxCgroupRoot))
+	judgeService.SetQueue(services.NewJudgeQueue(redisClient, int64(cfg.JudgeQueueMax)))
+	judgeService.SetTestParallelism(cfg.JudgeTestWorkers)
+	judgeService.SetRatingSystem(ratings)
+
+	languages, err := services.LoadLanguageRegistry(cfg.LanguagesConfig)
+	if err != nil {
+		log.Printf("Using built-in languages, failed to load %s: %v", cfg.LanguagesConfig, err)
+	} else {
+		judgeService.SetLanguages(languages)
+	}
+
+	compileCache, err := services.NewCompileCache(cfg.CompileCacheDir, int64(cfg.CompileCacheMaxMB)<<20)
+	if err != nil {
+		log.Printf("Compile cache disabled: %v", err)
+	} else {
+		judgeService.SetCompileCache(compileCache)
+	}
 	reportService := services.NewReportService(db)
 	skillCardService := services.NewSkillCardService(redisClient)
+	plagiarismService := services.NewPlagiarismService(db)
+	plagiarismService.SetThreshold(cfg.PlagiarismThreshold)
 
 	// Initialize handlers
 	handlers := handlers.NewHandlers(
@@ -50,7 +97,25 @@ func main() {
 		judgeService,
 		reportService,
 		skillCardService,
+		plagiarismService,
 	)
+	handlers.SetAdminToken(cfg.AdminToken)
+	if cfg.RunRateLimit > 0 {
+		handlers.SetRunLimiter(limiter.New(redisClient, "ratelimit:run:", cfg.RunRateLimit, time.Minute))
+	}
+
+	// Submit through the judge nodes when they are deployed apart
+	if cfg.JudgeAddr != "" {
+		judgeClient, err := services.NewJudgeClient(cfg.JudgeAddr)
+		if err != nil {
+			log.Fatal("Failed to create judge client:", err)
+		}
+		defer judgeClient.Close()
+		handlers.SetJudgeClient(judgeClient)
+	}
+
+	// Move matches on as their timers run out
+	go matchService.RunTimers(context.Background())
 
 	// Setup routes
 	router := gin.Default()
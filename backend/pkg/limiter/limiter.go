@@ -1,4 +1,47 @@
 package limiter
 
-// placeholder for rate limiter
+import (
+	"context"
+	"time"
 
+	"github.com/redis/go-redis/v9"
+)
+
+// allowScript counts an event in the key's current window, starting the
+// window on its first event, and returns the count and the window's
+// remaining time in milliseconds
+var allowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// Limiter allows a number of events per key in each fixed window. Counts
+// live in Redis so all API servers share them.
+type Limiter struct {
+	redis  *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+func New(redis *redis.Client, prefix string, limit int, window time.Duration) *Limiter {
+	return &Limiter{redis: redis, prefix: prefix, limit: limit, window: window}
+}
+
+// Allow counts an event for key and reports whether it is within the limit,
+// and if not, how long until the key may try again
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	values, err := allowScript.Run(ctx, l.redis, []string{l.prefix + key}, l.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	count, ttl := values[0], time.Duration(values[1])*time.Millisecond
+	if count > int64(l.limit) {
+		return false, max(ttl, 0), nil
+	}
+	return true, 0, nil
+}
@@ -1,4 +1,27 @@
 package middleware
 
-// placeholder for middleware package
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
 
+	"github.com/gin-gonic/gin"
+)
+
+// AdminToken only lets through requests that carry the admin token as
+// "Authorization: Bearer <token>". An empty token disables the routes.
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin API is disabled"})
+			return
+		}
+
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token required"})
+			return
+		}
+		c.Next()
+	}
+}